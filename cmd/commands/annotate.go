@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// AnnotateItems edits the tags and note of cached items matching the patterns.
+// A nil note leaves existing notes untouched; an empty one clears them.
+func AnnotateItems(patterns, addTags, removeTags []string, note *string, config types.Config) error {
+	if len(addTags) == 0 && len(removeTags) == 0 && note == nil {
+		return fmt.Errorf("nothing to change, use --tag, --untag or --note")
+	}
+
+	changed, err := helpers.AnnotateItems(patterns, addTags, removeTags, note, config)
+	if err != nil {
+		return fmt.Errorf("error updating index: %v", err)
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("No items matched %v\n", patterns)
+		return nil
+	}
+
+	for _, item := range changed {
+		fmt.Printf("Updated: %s (%s)\n", item.OriginalPath, item.ID)
+		if len(item.Tags) > 0 {
+			fmt.Printf("  Tags: %s\n", strings.Join(item.Tags, ", "))
+		}
+		if item.Note != "" {
+			fmt.Printf("  Note: %s\n", item.Note)
+		}
+	}
+	fmt.Printf("Updated %d item(s)\n", len(changed))
+	return nil
+}
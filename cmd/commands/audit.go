@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"fmt"
+
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// VerifyAudit checks the hash chain of the audited log records and prints
+// every problem found. It fails when there are any.
+func VerifyAudit(config types.Config) error {
+	report, err := helpers.VerifyAuditLog(config)
+	if err != nil {
+		return err
+	}
+
+	if report.Records == 0 {
+		if !config.Logging.Audit {
+			return fmt.Errorf("no audited records found, set audit = true under [logging] to start the chain")
+		}
+		fmt.Println("No audited records yet")
+		return nil
+	}
+
+	for _, p := range report.Problems {
+		location := p.File
+		if p.Line > 0 {
+			location = fmt.Sprintf("%s:%d", p.File, p.Line)
+		}
+		if p.Seq > 0 {
+			fmt.Printf("✗ %s: record %d: %s\n", location, p.Seq, p.Problem)
+		} else {
+			fmt.Printf("✗ %s: %s\n", location, p.Problem)
+		}
+	}
+
+	fmt.Printf("Checked %d audited record(s), %d to %d\n", report.Records, report.FirstSeq, report.LastSeq)
+	if report.FirstSeq > 1 {
+		fmt.Printf("Records before %d were rotated out of the log\n", report.FirstSeq)
+	}
+	if report.Unaudited > 0 {
+		fmt.Printf("%d older record(s) were written before audit mode and are not covered\n", report.Unaudited)
+	}
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("audit chain is broken: %d problem(s)", len(report.Problems))
+	}
+	fmt.Println("✓ Audit chain intact")
+	return nil
+}
@@ -0,0 +1,680 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// flagDef describes a single command line flag.
+type flagDef struct {
+	Names    []string // every spelling, e.g. {"-f", "--noconfirm"}
+	Value    string   // placeholder shown in help for flags taking a value, "" for booleans
+	Help     string
+	Apply    func(p *ParsedArgs, value string)
+	Complete string // what the value completes to, see completeKind
+}
+
+// commandDef describes a subcommand, the legacy flags that select it and
+// the flags it accepts. Help text is generated from these definitions.
+type commandDef struct {
+	Name      string
+	Aliases   []string // legacy flag spellings, e.g. -r and --restore
+	Args      string   // argument synopsis for help text
+	Summary   string
+	Flags     []flagDef
+	MinArgs   int
+	MaxArgs   int      // -1 for unlimited
+	Implicit  bool     // selected by giving files only, not by name
+	OwnConfig bool     // loads the config file itself, so it runs while the file has errors
+	Hidden    bool     // left out of help and completion
+	Complete  string   // what positional arguments complete to, see completeKind
+	Choices   []string // fixed positional values, completed and shown in help
+	Validate  func(p ParsedArgs) error
+	Run       func(p ParsedArgs, cfg types.Config) error // nil: main runs the operation
+	RunRaw    func(args []string, cfg types.Config) int  // parses its own arguments and returns the exit code
+}
+
+// Flags shared by several commands
+var (
+	helpFlag = flagDef{
+		Names: []string{"-h", "--help"},
+		Help:  "Show help for the command",
+		Apply: func(p *ParsedArgs, _ string) { p.Help = true },
+	}
+	verboseFlag = flagDef{
+		Names: []string{"-v"},
+		Help:  "Log info records even if [logging] level is higher",
+		Apply: func(p *ParsedArgs, _ string) { p.Verbosity = max(p.Verbosity, 1) },
+	}
+	debugFlag = flagDef{
+		Names: []string{"-vv"},
+		Help:  "Log debug traces of file operations and index timings",
+		Apply: func(p *ParsedArgs, _ string) { p.Verbosity = 2 },
+	}
+	setFlag = flagDef{
+		Names: []string{"--set"},
+		Value: "<key=value>",
+		Help:  "Override a config setting for this run (repeatable)",
+		Apply: func(*ParsedArgs, string) {}, // read before parsing, see ConfigOverrides
+	}
+	noConfirmFlag = flagDef{
+		Names: []string{"-f", "--noconfirm"},
+		Help:  "Skip confirmation prompts",
+		Apply: func(p *ParsedArgs, _ string) { p.NoConfirm = true },
+	}
+	quietFlag = flagDef{
+		Names: []string{"-q", "--quiet"},
+		Help:  "Run without UI and without confirmation",
+		Apply: func(p *ParsedArgs, _ string) { p.Headless, p.NoConfirm = true, true },
+	}
+	headlessFlag = flagDef{
+		Names: []string{"--headless", "--no-tui"},
+		Help:  "Run without UI",
+		Apply: func(p *ParsedArgs, _ string) { p.Headless = true },
+	}
+	dryRunFlag = flagDef{
+		Names: []string{"--dry-run"},
+		Help:  "Show what would happen without changing anything",
+		Apply: func(p *ParsedArgs, _ string) { p.DryRun = true },
+	}
+	withThemeFlag = flagDef{
+		Names: []string{"--with-theme"},
+		Help:  "With export, write the theme's colors into the file",
+		Apply: func(p *ParsedArgs, _ string) { p.WithTheme = true },
+	}
+	noHooksFlag = flagDef{
+		Names: []string{"--no-hooks"},
+		Help:  "Don't run the [hooks] commands",
+		Apply: func(p *ParsedArgs, _ string) { p.NoHooks = true },
+	}
+	allowProtectedFlag = flagDef{
+		Names: []string{"--allow-protected"},
+		Help:  "Allow deleting protected paths (home, mounts, [safety])",
+		Apply: func(p *ParsedArgs, _ string) { p.AllowProtected = true },
+	}
+	allowUncommittedFlag = flagDef{
+		Names: []string{"--allow-uncommitted"},
+		Help:  "Delete files git has not committed without typing 'yes'",
+		Apply: func(p *ParsedArgs, _ string) { p.AllowUncommitted = true },
+	}
+	tagFlag = flagDef{
+		Names:    []string{"--tag"},
+		Value:    "<name>",
+		Help:     "Tag deleted items (repeatable)",
+		Apply:    func(p *ParsedArgs, v string) { p.Tags = append(p.Tags, v) },
+		Complete: completeTags,
+	}
+	filterTagFlag = flagDef{
+		Names:    []string{"--tag"},
+		Value:    "<name>",
+		Help:     "Only items carrying this tag (repeatable)",
+		Apply:    func(p *ParsedArgs, v string) { p.Tags = append(p.Tags, v) },
+		Complete: completeTags,
+	}
+	noteFlag = flagDef{
+		Names: []string{"--note"},
+		Value: "<text>",
+		Help:  "Attach a reason to deleted items",
+		Apply: func(p *ParsedArgs, v string) { p.Note, p.NoteSet = v, true },
+	}
+)
+
+// stdinFlags read more arguments (what) from stdin, for pipes from find or
+// git ls-files.
+func stdinFlags(what string) []flagDef {
+	return []flagDef{
+		{
+			Names: []string{"--stdin"},
+			Help:  "Read " + what + " from stdin, one per line",
+			Apply: func(p *ParsedArgs, _ string) { p.StdinMode = "lines" },
+		},
+		{
+			Names: []string{"--stdin0"},
+			Help:  "Read NUL-separated " + what + " from stdin (find -print0)",
+			Apply: func(p *ParsedArgs, _ string) { p.StdinMode = "nul" },
+		},
+	}
+}
+
+// matchFlags turn the files of a delete into roots that are walked for
+// matching paths.
+var matchFlags = []flagDef{
+	{
+		Names: []string{"--match"},
+		Value: "<glob>",
+		Help:  "Delete paths below the given dirs (default .) matching glob (repeatable)",
+		Apply: func(p *ParsedArgs, v string) { p.Match = append(p.Match, v) },
+	},
+	{
+		Names: []string{"--exclude"},
+		Value: "<glob>",
+		Help:  "With --match or a filter, skip paths and subtrees matching glob (repeatable)",
+		Apply: func(p *ParsedArgs, v string) { p.Exclude = append(p.Exclude, v) },
+	},
+	{
+		Names: []string{"--one-file-system"},
+		Help:  "With --match or a filter, stay on the filesystem of each dir",
+		Apply: func(p *ParsedArgs, _ string) { p.OneFileSystem = true },
+	},
+}
+
+// filterFlags select the direct entries of the given dirs, or narrow down
+// --match, by age, size and type.
+var filterFlags = []flagDef{
+	{
+		Names: []string{"--older-than"},
+		Value: "<age>",
+		Help:  "Only entries modified more than age ago (30d, 2w, 12h)",
+		Apply: func(p *ParsedArgs, v string) { p.OlderThan = v },
+	},
+	{
+		Names: []string{"--newer-than"},
+		Value: "<age>",
+		Help:  "Only entries modified less than age ago",
+		Apply: func(p *ParsedArgs, v string) { p.NewerThan = v },
+	},
+	{
+		Names: []string{"--larger-than"},
+		Value: "<size>",
+		Help:  "Only entries larger than size (100MB, 1.5G, 512k)",
+		Apply: func(p *ParsedArgs, v string) { p.LargerThan = v },
+	},
+	{
+		Names: []string{"--smaller-than"},
+		Value: "<size>",
+		Help:  "Only entries smaller than size",
+		Apply: func(p *ParsedArgs, v string) { p.SmallerThan = v },
+	},
+	{
+		Names: []string{"--type"},
+		Value: "<f|d|l>",
+		Help:  "Only files, directories or symlinks",
+		Apply: func(p *ParsedArgs, v string) { p.Type = v },
+	},
+	{
+		Names: []string{"--atime"},
+		Help:  "Use the access time for --older-than/--newer-than",
+		Apply: func(p *ParsedArgs, _ string) { p.Atime = true },
+	},
+}
+
+// globalFlags are accepted by every command.
+var globalFlags = []flagDef{helpFlag, verboseFlag, debugFlag, setFlag}
+
+// runFlags are accepted by every command that changes the cache.
+var runFlags = []flagDef{noConfirmFlag, quietFlag, headlessFlag, dryRunFlag, noHooksFlag}
+
+var cliCommands []commandDef
+
+func init() {
+	cliCommands = []commandDef{
+		{
+			Name:     "delete",
+			Args:     "<files...>",
+			Summary:  "Move files or directories to the cache",
+			Flags:    concatFlags([]flagDef{allowProtectedFlag, allowUncommittedFlag, tagFlag, noteFlag}, matchFlags, filterFlags, stdinFlags("paths"), runFlags),
+			MinArgs:  1,
+			MaxArgs:  -1,
+			Implicit: true,
+			Complete: completeFiles,
+		},
+		{
+			Name:    "rm",
+			Args:    "[-rfiIdv] <files...>",
+			Summary: "rm-compatible delete, also used when invoked as rm",
+			RunRaw:  RunRm,
+		},
+		{
+			Name:     "restore",
+			Aliases:  []string{"-r", "--restore"},
+			Args:     "<pattern>...",
+			Summary:  "Restore cached items matching pattern(s)",
+			Complete: completeItems,
+			Flags:    concatFlags([]flagDef{filterTagFlag}, stdinFlags("patterns or IDs"), runFlags),
+			MaxArgs:  -1,
+			Validate: func(p ParsedArgs) error {
+				if len(p.Filenames) == 0 && len(p.Tags) == 0 {
+					return fmt.Errorf("restore requires at least one pattern or --tag")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"-l", "--list"},
+			Summary: "Show all cached items",
+			Flags:   []flagDef{filterTagFlag},
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				return ShowList(cfg, p.Tags)
+			},
+		},
+		{
+			Name:     "info",
+			Aliases:  []string{"-i", "--info"},
+			Args:     "<pattern>...",
+			Summary:  "Show detailed info about cached items",
+			Complete: completeItems,
+			Flags:    []flagDef{filterTagFlag},
+			MaxArgs:  -1,
+			Validate: func(p ParsedArgs) error {
+				if len(p.Filenames) == 0 && len(p.Tags) == 0 {
+					return fmt.Errorf("info requires a pattern or --tag")
+				}
+				return nil
+			},
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				return ShowInfo(p.Filenames, p.Tags, cfg)
+			},
+		},
+		{
+			Name:    "purge",
+			Aliases: []string{"-pr", "--purge"},
+			Args:    "<days>",
+			Summary: "Delete cached items older than N days",
+			Flags:   runFlags,
+			MinArgs: 1,
+			MaxArgs: 1,
+		},
+		{
+			Name:    "clear",
+			Aliases: []string{"-c", "--clear"},
+			Summary: "Clear the entire cache immediately",
+			Flags:   runFlags,
+		},
+		{
+			Name:     "pin",
+			Aliases:  []string{"--pin"},
+			Args:     "<pattern>...",
+			Summary:  "Keep matching items until unpinned",
+			Complete: completeItems,
+			MinArgs:  1,
+			MaxArgs:  -1,
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				return PinItems(p.Filenames, true, cfg)
+			},
+		},
+		{
+			Name:     "unpin",
+			Aliases:  []string{"--unpin"},
+			Args:     "<pattern>...",
+			Summary:  "Let matching items expire again",
+			Complete: completeItems,
+			MinArgs:  1,
+			MaxArgs:  -1,
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				return PinItems(p.Filenames, false, cfg)
+			},
+		},
+		{
+			Name:     "annotate",
+			Aliases:  []string{"--annotate"},
+			Args:     "<pattern>...",
+			Summary:  "Edit tags and note of cached items",
+			Complete: completeItems,
+			Flags: []flagDef{
+				{
+					Names:    []string{"--tag"},
+					Value:    "<name>",
+					Help:     "Add a tag (repeatable)",
+					Apply:    tagFlag.Apply,
+					Complete: completeTags,
+				},
+				{
+					Names:    []string{"--untag"},
+					Value:    "<name>",
+					Help:     "Remove a tag (repeatable)",
+					Apply:    func(p *ParsedArgs, v string) { p.Untags = append(p.Untags, v) },
+					Complete: completeTags,
+				},
+				{
+					Names: []string{"--note"},
+					Value: "<text>",
+					Help:  "Replace the note, an empty text removes it",
+					Apply: noteFlag.Apply,
+				},
+			},
+			MinArgs: 1,
+			MaxArgs: -1,
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				var note *string
+				if p.NoteSet {
+					note = &p.Note
+				}
+				return AnnotateItems(p.Filenames, p.Tags, p.Untags, note, cfg)
+			},
+		},
+		{
+			Name:    "stats",
+			Aliases: []string{"-s", "--stats"},
+			Summary: "Show cache statistics",
+			Run: func(_ ParsedArgs, cfg types.Config) error {
+				return ShowStats(cfg)
+			},
+		},
+		{
+			Name:    "history",
+			Aliases: []string{"--history"},
+			Summary: "Show the logged operations, restore items from them",
+			Flags: []flagDef{
+				{
+					Names:    []string{"--op"},
+					Value:    "<operation>",
+					Help:     "Only delete, restore, purge, cleanup or clear entries (repeatable)",
+					Apply:    func(p *ParsedArgs, v string) { p.Ops = append(p.Ops, v) },
+					Complete: completeOps,
+				},
+				{
+					Names: []string{"--since"},
+					Value: "<time>",
+					Help:  "Only entries from this date (2026-01-31) or age (7d) on",
+					Apply: func(p *ParsedArgs, v string) { p.Since = v },
+				},
+				{
+					Names: []string{"--until"},
+					Value: "<time>",
+					Help:  "Only entries before this date or age, a date includes its day",
+					Apply: func(p *ParsedArgs, v string) { p.Until = v },
+				},
+				{
+					Names:    []string{"--path"},
+					Value:    "<text>",
+					Help:     "Only entries whose original path contains text",
+					Apply:    func(p *ParsedArgs, v string) { p.Path = v },
+					Complete: completeFiles,
+				},
+				{
+					Names: []string{"--plain"},
+					Help:  "Print one line per entry, the default when not on a terminal",
+					Apply: func(p *ParsedArgs, _ string) { p.Output = "plain" },
+				},
+				{
+					Names: []string{"--json"},
+					Help:  "Print the entries as a JSON array",
+					Apply: func(p *ParsedArgs, _ string) { p.Output = "json" },
+				},
+			},
+			Validate: func(p ParsedArgs) error {
+				_, err := p.historyFilter()
+				return err
+			},
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				filter, _ := p.historyFilter()
+				return ShowHistory(filter, p.Output, p.Options(), cfg)
+			},
+		},
+		{
+			Name:    "audit-verify",
+			Aliases: []string{"--audit-verify"},
+			Summary: "Check the hash chain of the audit log",
+			Run: func(_ ParsedArgs, cfg types.Config) error {
+				return VerifyAudit(cfg)
+			},
+		},
+		{
+			Name:      "config",
+			Aliases:   []string{"-cp", "--config-path"},
+			Args:      "[path|check|get <key>|set <key> <value>|edit|show|export [file]|import <file>|backups|rollback [backup]]",
+			Summary:   "Print, check, change, export or import the config file",
+			Choices:   []string{"path", "check", "get", "set", "edit", "show", "export", "import", "backups", "rollback"},
+			MaxArgs:   3,
+			OwnConfig: true,
+			Complete:  completeConfigKeys,
+			Flags: []flagDef{
+				{
+					Names: []string{"--effective"},
+					Help:  "With show, print the merged configuration vanish runs with",
+					Apply: func(p *ParsedArgs, _ string) { p.Effective = true },
+				},
+				withThemeFlag,
+				noConfirmFlag,
+				dryRunFlag,
+			},
+			Validate: validateConfigArgs,
+			Run: func(p ParsedArgs, _ types.Config) error {
+				return RunConfig(p)
+			},
+		},
+		{
+			// Spellings from before vx config export
+			Name:      "export-config",
+			Aliases:   []string{"-ex", "--export-config"},
+			Args:      "[file]",
+			Summary:   "Same as config export",
+			MaxArgs:   1,
+			OwnConfig: true,
+			Hidden:    true,
+			Flags:     []flagDef{withThemeFlag},
+			Run: func(p ParsedArgs, _ types.Config) error {
+				p.Filenames = append([]string{"export"}, p.Filenames...)
+				return RunConfig(p)
+			},
+		},
+		{
+			Name:      "import-config",
+			Aliases:   []string{"-ic", "--import-config"},
+			Args:      "<file>",
+			Summary:   "Same as config import",
+			MinArgs:   1,
+			MaxArgs:   1,
+			OwnConfig: true,
+			Hidden:    true,
+			Flags:     []flagDef{noConfirmFlag, dryRunFlag},
+			Run: func(p ParsedArgs, _ types.Config) error {
+				p.Filenames = append([]string{"import"}, p.Filenames...)
+				return RunConfig(p)
+			},
+		},
+		{
+			Name:    "path",
+			Aliases: []string{"-p", "--path"},
+			Summary: "Print the cache directory path",
+			Run: func(_ ParsedArgs, cfg types.Config) error {
+				fmt.Println(helpers.ExpandPath(cfg.Cache.Directory))
+				return nil
+			},
+		},
+		{
+			Name:     "themes",
+			Aliases:  []string{"-t", "--themes"},
+			Args:     "[name]",
+			Summary:  "Preview all themes, or a single one",
+			MaxArgs:  1,
+			Complete: completeThemes,
+			Run: func(p ParsedArgs, _ types.Config) error {
+				if len(p.Filenames) == 1 {
+					return ShowThemePreview(p.Filenames[0])
+				}
+				ShowThemesWithTuiPreview(&MainThemeDisplayer{})
+				return nil
+			},
+		},
+		{
+			Name:    "version",
+			Aliases: []string{"-V", "--version"},
+			Summary: "Show version information",
+			Run: func(_ ParsedArgs, _ types.Config) error {
+				ShowVersion()
+				return nil
+			},
+		},
+		{
+			Name:     "help",
+			Args:     "[command]",
+			Summary:  "Show help for vx or a command",
+			MaxArgs:  1,
+			Complete: completeCommands,
+			Run: func(p ParsedArgs, cfg types.Config) error {
+				if len(p.Filenames) == 0 {
+					ShowUsageSmart(cfg)
+					return nil
+				}
+				cmd := commandByName(p.Filenames[0])
+				if cmd == nil || cmd.Hidden {
+					return fmt.Errorf("unknown command '%s'", p.Filenames[0])
+				}
+				if cmd.Name == "rm" {
+					showRmUsage()
+					return nil
+				}
+				ShowCommandUsage(cmd, cfg)
+				return nil
+			},
+		},
+		{
+			Name:    "gen-docs",
+			Aliases: []string{"--gen-docs"},
+			Args:    "<dir>",
+			Summary: "Generate the man page and markdown references into a directory",
+			MinArgs: 1,
+			MaxArgs: 1,
+			Run: func(p ParsedArgs, _ types.Config) error {
+				return GenerateDocs(p.Filenames[0])
+			},
+		},
+		{
+			Name:    "completion",
+			Args:    "<shell>",
+			Summary: "Print the completion script for bash, zsh or fish",
+			Choices: completionShells,
+			MinArgs: 1,
+			MaxArgs: 1,
+			Run: func(p ParsedArgs, _ types.Config) error {
+				return ShowCompletion(p.Filenames[0])
+			},
+		},
+		{
+			Name:    "__complete",
+			Summary: "Print completion candidates for the given words",
+			Hidden:  true,
+			RunRaw:  RunComplete,
+		},
+	}
+}
+
+// LoadsOwnConfig reports whether the command selected by args reads the
+// config file itself, so main runs it even when the file has errors.
+func LoadsOwnConfig(args []string) bool {
+	cmd, _, err := findCommand(args)
+	return err == nil && cmd.OwnConfig
+}
+
+// ConfigOverrides returns the values of every --set in args and whether
+// --dry-run is given. main needs them to load the config, before args can
+// be parsed.
+func ConfigOverrides(args []string) (sets []string, dryRun bool) {
+	if len(args) > 0 {
+		if cmd := commandByName(args[0]); cmd != nil && cmd.RunRaw != nil {
+			return nil, false
+		}
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return sets, dryRun
+		case arg == "--set" && i+1 < len(args):
+			i++
+			sets = append(sets, args[i])
+		case strings.HasPrefix(arg, "--set="):
+			sets = append(sets, strings.TrimPrefix(arg, "--set="))
+		case arg == "--dry-run":
+			dryRun = true
+		case isFlag(arg) && !strings.Contains(arg, "=") && takesValue(arg):
+			i++
+		}
+	}
+	return sets, dryRun
+}
+
+// concatFlags joins flag sets into a new slice.
+func concatFlags(sets ...[]flagDef) []flagDef {
+	var flags []flagDef
+	for _, set := range sets {
+		flags = append(flags, set...)
+	}
+	return flags
+}
+
+// commandByName returns the command selected by a subcommand word.
+func commandByName(name string) *commandDef {
+	for i := range cliCommands {
+		if !cliCommands[i].Implicit && cliCommands[i].Name == name {
+			return &cliCommands[i]
+		}
+	}
+	return nil
+}
+
+// commandByAlias returns the command selected by a legacy flag such as -r.
+func commandByAlias(arg string) *commandDef {
+	for i := range cliCommands {
+		for _, alias := range cliCommands[i].Aliases {
+			if alias == arg {
+				return &cliCommands[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (c *commandDef) isAlias(arg string) bool {
+	for _, alias := range c.Aliases {
+		if alias == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupFlag finds the flag named name among the command's and the global flags.
+func (c *commandDef) lookupFlag(name string) *flagDef {
+	for _, set := range [][]flagDef{c.Flags, globalFlags} {
+		for i := range set {
+			for _, n := range set[i].Names {
+				if n == name {
+					return &set[i]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// usageName is how the command is spelled in help and error hints.
+func (c *commandDef) usageName() string {
+	if c == nil || c.Implicit {
+		return "vx"
+	}
+	return "vx " + c.Name
+}
+
+// takesValue reports whether any command defines name as a flag with a
+// value. It is used to skip flag values while the command is not known yet.
+func takesValue(name string) bool {
+	for i := range cliCommands {
+		if f := cliCommands[i].lookupFlag(name); f != nil && f.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isFlag(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-'
+}
+
+// exitWithUsageError prints a parse error with a pointer to the right help
+// and exits.
+func exitWithUsageError(err error, cmd *commandDef) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.usageName())
+	os.Exit(1)
+}
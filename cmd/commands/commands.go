@@ -5,9 +5,13 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+
+	"golang.org/x/term"
 
 	"vanish/internal/helpers"
 	"vanish/internal/types"
@@ -20,126 +24,352 @@ type ParsedArgs struct {
 	Filenames []string
 	NoConfirm bool
 	Headless  bool
+	Tags      []string // --tag values: attached on delete, filters on restore/info/list
+	Untags    []string // --untag values for --annotate
+	Note      string
+	NoteSet   bool // --note was given, even if empty (clears the note on --annotate)
+
+	AllowProtected   bool
+	AllowUncommitted bool
+	DryRun           bool
+	NoHooks          bool
+	Help             bool   // -h/--help was given for the command
+	Verbosity        int    // 1 for -v, 2 for -vv
+	StdinMode        string // "lines" or "nul" when --stdin/--stdin0 adds arguments from stdin
+
+	Match         []string // --match globs: the files become roots to walk
+	Exclude       []string // --exclude globs pruning the walk
+	OneFileSystem bool
+	OlderThan     string // raw filter values, parsed by entryFilter
+	NewerThan     string
+	LargerThan    string
+	SmallerThan   string
+	Type          string
+	Atime         bool
+	BatchID       string // set when the files were collected by --match or a filter
+
+	Ops    []string // history --op values
+	Since  string   // history --since/--until, parsed by historyFilter
+	Until  string
+	Path   string // history --path
+	Output string // "plain" or "json" instead of the interactive view
+
+	Effective bool // config show --effective
+	WithTheme bool // config export --with-theme
 }
 
-// ParseArgs parses the command-line arguments and returns the operation, filenames, and flags
+// Options returns the per-invocation options passed on to the TUI and headless runners.
+func (p ParsedArgs) Options() types.Options {
+	return types.Options{
+		NoConfirm:        p.NoConfirm,
+		AllowProtected:   p.AllowProtected,
+		AllowUncommitted: p.AllowUncommitted,
+		Tags:             p.Tags,
+		Note:             p.Note,
+		DryRun:           p.DryRun,
+		BatchID:          p.BatchID,
+		NoHooks:          p.NoHooks,
+		Verbosity:        p.Verbosity,
+	}
+}
+
+// errRmFlags signals that the arguments use rm-only flags and belong to rm mode.
+var errRmFlags = errors.New("rm flags")
+
+// ParseArgs parses the command-line arguments. Commands that only display
+// or edit metadata (list, info, stats, pin, ...) are run here and exit; the
+// operations main runs (delete, restore, purge, clear) are returned.
 func ParseArgs(args []string, cfg types.Config) ParsedArgs {
-	var operation string
-	var filenames []string
-	var noConfirm bool
-	var headless bool
+	// vx rm ... behaves exactly like rm, vx __complete ... takes raw words
+	if len(args) > 0 {
+		if cmd := commandByName(args[0]); cmd != nil && cmd.RunRaw != nil {
+			os.Exit(cmd.RunRaw(args[1:], cfg))
+		}
+	}
 
-	for i := 0; i < len(args); i++ {
-		arg := args[i]
-		switch arg {
-		case "-h", "--help":
+	// With alias rm=vx, "rm -r build/" removes build/ instead of restoring
+	if IsRmCommandLine(args) {
+		os.Exit(RunRm(args, cfg))
+	}
+
+	parsed, cmd, err := parseArgs(args, cfg)
+	if errors.Is(err, errRmFlags) {
+		// With alias rm=vx, rm-only flags like -rf must not become filenames
+		os.Exit(RunRm(args, cfg))
+	}
+	if parsed.Help {
+		if cmd.Implicit {
 			ShowUsageSmart(cfg)
-			os.Exit(0)
-		case "-t", "--themes":
-			displayer := &MainThemeDisplayer{}
-			ShowThemesWithTuiPreview(displayer)
-			os.Exit(0)
-		case "-p", "--path":
-			fmt.Println(helpers.ExpandPath(cfg.Cache.Directory))
-			os.Exit(0)
-		case "-cp", "--config-path":
-			fmt.Println(helpers.GetConfigPath())
-			os.Exit(0)
-		case "-l", "--list":
-			if err := ShowList(cfg); err != nil {
-				log.Fatalf("Error: %v", err)
-			}
-			os.Exit(0)
-		case "-v", "--version":
-			ShowVersion()
-			os.Exit(0)
-		case "-s", "--stats":
-			if err := ShowStats(cfg); err != nil {
-				log.Fatalf("Error: %v", err)
-			}
-			os.Exit(0)
-		case "-c", "--clear":
-			operation = "clear"
-			filenames = []string{""}
-		case "-f", "--noconfirm":
-			noConfirm = true
-		case "-q", "--quiet":
-			headless = true
-			noConfirm = true
-		case "-r", "--restore":
-			operation = "restore"
-			if i+1 < len(args) {
-				filenames = args[i+1:]
-				i = len(args) // consume remaining args
-			} else {
-				log.Fatal("Error: --restore requires at least one pattern")
-			}
-		case "-i", "--info":
-			if i+1 < len(args) {
-				if err := ShowInfo(args[i+1], cfg); err != nil {
-					log.Fatalf("Error: %v", err)
-				}
-			} else {
-				log.Fatal("Error: --info requires a pattern")
+		} else {
+			ShowCommandUsage(cmd, cfg)
+		}
+		os.Exit(0)
+	}
+	if err != nil {
+		exitWithUsageError(err, cmd)
+	}
+
+	if cmd.Run != nil {
+		cfg = helpers.WithVerbosity(cfg, parsed.Verbosity)
+		if err := cmd.Run(parsed, cfg); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	return parsed
+}
+
+// parseArgs resolves the command and parses its flags and arguments. Flags
+// may appear anywhere before --. The command is either the first positional
+// word (vx restore ...), a legacy flag anywhere (vx -r ...), or delete when
+// only files are given. The command is returned even on error so the caller
+// can point at the right help.
+func parseArgs(args []string, cfg types.Config) (ParsedArgs, *commandDef, error) {
+	var parsed ParsedArgs
+
+	cmd, rest, err := findCommand(args)
+	if err != nil {
+		return parsed, nil, err
+	}
+	parsed.Operation = cmd.Name
+
+	var positional []string
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if arg == "--" {
+			// Everything after -- is a filename or pattern, even if it starts with -
+			positional = append(positional, rest[i+1:]...)
+			break
+		}
+		if !isFlag(arg) {
+			positional = append(positional, arg)
+			continue
+		}
+		if cmd.isAlias(arg) {
+			continue
+		}
+
+		name, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue = strings.Cut(arg, "=")
+		}
+
+		flag := cmd.lookupFlag(name)
+		if flag == nil {
+			if cmd.Implicit && IsRmFlag(arg) {
+				return parsed, cmd, errRmFlags
 			}
-			os.Exit(0)
-		case "-pr", "--purge":
-			if i+1 < len(args) {
-				operation = "purge"
-				filenames = []string{args[i+1]}
-				i++ // skip value
-			} else {
-				log.Fatal("Error: --purge requires number of days")
+			if other := commandByAlias(arg); other != nil {
+				return parsed, cmd, fmt.Errorf("%s cannot be combined with %s", arg, cmd.Name)
 			}
-		default:
-			// If no operation is set yet, assume delete
-			if operation == "" {
-				operation = "delete"
-				filenames = args[i:]
-				i = len(args) // consume all
+			return parsed, cmd, fmt.Errorf("unknown flag '%s' for '%s'", arg, cmd.usageName())
+		}
+
+		switch {
+		case flag.Value != "" && !hasValue:
+			if i+1 >= len(rest) {
+				return parsed, cmd, fmt.Errorf("%s requires a value %s", name, flag.Value)
 			}
+			i++
+			value = rest[i]
+		case flag.Value == "" && hasValue:
+			return parsed, cmd, fmt.Errorf("%s does not take a value", name)
 		}
-		if operation == "restore" || operation == "delete" {
-			break
+		flag.Apply(&parsed, value)
+	}
+	parsed.Filenames = positional
+
+	if parsed.Help {
+		return parsed, cmd, nil
+	}
+
+	if parsed.StdinMode != "" {
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			return parsed, cmd, fmt.Errorf("--stdin expects input from a pipe or file, not a terminal")
+		}
+		read, err := helpers.ReadPaths(os.Stdin, parsed.StdinMode == "nul")
+		if err != nil {
+			return parsed, cmd, err
 		}
+		positional = append(positional, read...)
+		parsed.Filenames = positional
 	}
 
-	// Fallback to delete if nothing else is matched
-	if operation == "" && len(filenames) == 0 && len(args) > 0 {
-		operation = "delete"
-		for _, arg := range args {
-			if arg != "--noconfirm" && arg != "-f" &&
-				arg != "--headless" && arg != "--no-tui" &&
-				arg != "-q" && arg != "--quiet" {
-				filenames = append(filenames, arg)
-			}
+	filter, err := parsed.entryFilter()
+	if err != nil {
+		return parsed, cmd, err
+	}
+	if len(parsed.Match) > 0 || !filter.IsZero() {
+		if positional, err = expandSelection(&parsed, positional, filter, cfg); err != nil {
+			return parsed, cmd, err
+		}
+		parsed.Filenames = positional
+	} else if len(parsed.Exclude) > 0 || parsed.OneFileSystem {
+		return parsed, cmd, fmt.Errorf("--exclude and --one-file-system require --match or a filter")
+	}
+
+	switch {
+	case len(positional) < cmd.MinArgs && cmd.Implicit:
+		return parsed, cmd, fmt.Errorf("no files or directories given")
+	case len(positional) < cmd.MinArgs:
+		return parsed, cmd, fmt.Errorf("%s requires %s", cmd.Name, cmd.Args)
+	case cmd.MaxArgs >= 0 && len(positional) > cmd.MaxArgs:
+		if cmd.MaxArgs == 0 {
+			return parsed, cmd, fmt.Errorf("%s takes no arguments, got %q", cmd.Name, positional)
+		}
+		return parsed, cmd, fmt.Errorf("%s takes at most %d argument(s), got %d", cmd.Name, cmd.MaxArgs, len(positional))
+	}
+	if cmd.Validate != nil {
+		if err := cmd.Validate(parsed); err != nil {
+			return parsed, cmd, err
+		}
+	}
+
+	return parsed, cmd, nil
+}
+
+// entryFilter parses the --older-than, --larger-than, ... values.
+func (p ParsedArgs) entryFilter() (helpers.EntryFilter, error) {
+	var filter helpers.EntryFilter
+	var err error
+
+	if p.OlderThan != "" {
+		if filter.OlderThan, err = helpers.ParseAge(p.OlderThan); err != nil {
+			return filter, err
+		}
+	}
+	if p.NewerThan != "" {
+		if filter.NewerThan, err = helpers.ParseAge(p.NewerThan); err != nil {
+			return filter, err
+		}
+	}
+	if p.LargerThan != "" {
+		if filter.LargerThan, err = helpers.ParseSize(p.LargerThan); err != nil {
+			return filter, err
+		}
+	}
+	if p.SmallerThan != "" {
+		if filter.SmallerThan, err = helpers.ParseSize(p.SmallerThan); err != nil {
+			return filter, err
+		}
+	}
+
+	switch p.Type {
+	case "", "f", "d", "l":
+		filter.Type = p.Type
+	default:
+		return filter, fmt.Errorf("invalid type '%s', use f (file), d (directory) or l (symlink)", p.Type)
+	}
+
+	if p.Atime && filter.OlderThan == 0 && filter.NewerThan == 0 {
+		return filter, fmt.Errorf("--atime requires --older-than or --newer-than")
+	}
+	filter.UseAtime = p.Atime
+
+	return filter, nil
+}
+
+// historyFilter parses the history --op, --since, --until and --path values.
+func (p ParsedArgs) historyFilter() (helpers.HistoryFilter, error) {
+	filter := helpers.HistoryFilter{Path: p.Path}
+	var err error
+
+	for _, value := range p.Ops {
+		op, err := helpers.ParseHistoryOperation(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Operations = append(filter.Operations, op)
+	}
+	if p.Since != "" {
+		if filter.Since, err = helpers.ParseHistoryTime(p.Since, false); err != nil {
+			return filter, err
+		}
+	}
+	if p.Until != "" {
+		if filter.Until, err = helpers.ParseHistoryTime(p.Until, true); err != nil {
+			return filter, err
 		}
 	}
+	return filter, nil
+}
+
+// expandSelection walks the roots (the current directory if none are given)
+// and returns the paths selected by --match, --exclude and the filters. The
+// paths are deleted as one batch.
+func expandSelection(parsed *ParsedArgs, roots []string, filter helpers.EntryFilter, cfg types.Config) ([]string, error) {
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
 
-	return ParsedArgs{
-		Operation: operation,
-		Filenames: filenames,
-		NoConfirm: noConfirm,
-		Headless:  headless,
+	matches, err := helpers.FindMatches(roots, helpers.MatchOptions{
+		Match:          parsed.Match,
+		Exclude:        parsed.Exclude,
+		Filter:         filter,
+		OneFileSystem:  parsed.OneFileSystem,
+		AllowProtected: parsed.AllowProtected,
+	}, cfg)
+	if err != nil {
+		return nil, err
 	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("nothing under %s matches the selection", strings.Join(roots, ", "))
+	}
+
+	parsed.BatchID = helpers.NewBatchID()
+	return matches, nil
 }
 
-// FUTURE Case
-// case "-ex","--export-config":
-// 	var exportPath string
-// if len(args) > 1 {
-// 	exportPath = args[1]
-// }
-// if err := config.ExportConfig(exportPath); err != nil {
-// 	fmt.Printf("Export failed: %v\n", err)
-// 	os.Exit(1)
-// }
-// case "-ic","import-config":
-// 	if len(args) < 2 {
-// 		fmt.Println("Error --import-config needs a file path")
-// 		os.Exit(1)
-// 	}
-// 	if err := config.ImportConfig(args[1]); err != nil {
-// 		fmt.Printf("Import failed: %v\n", err)
-// 		os.Exit(1)
-// 	}
+// findCommand picks the command and returns the arguments left to parse.
+func findCommand(args []string) (*commandDef, []string, error) {
+	var cmd *commandDef
+	rest := args
+
+	// A subcommand word is the first positional argument
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if isFlag(arg) {
+			if !strings.Contains(arg, "=") && takesValue(arg) {
+				i++
+			}
+			continue
+		}
+		if named := commandByName(arg); named != nil && named.RunRaw == nil {
+			cmd = named
+			rest = append(append([]string{}, args[:i]...), args[i+1:]...)
+		}
+		break
+	}
+
+	// Legacy flags (-r, --list, ...) select a command from anywhere
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if arg == "--" {
+			break
+		}
+		if !isFlag(arg) {
+			continue
+		}
+		if !strings.Contains(arg, "=") && takesValue(arg) {
+			i++
+			continue
+		}
+		aliased := commandByAlias(arg)
+		if aliased == nil || aliased == cmd {
+			continue
+		}
+		if cmd != nil {
+			return nil, nil, fmt.Errorf("conflicting commands '%s' and '%s'", cmd.Name, aliased.Name)
+		}
+		cmd = aliased
+	}
+
+	if cmd == nil {
+		cmd = &cliCommands[0] // delete
+	}
+	return cmd, rest, nil
+}
@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+func TestIsRmCommandLine(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	os.MkdirAll(filepath.Join(dir, "build", "obj"), 0755)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"Recursive on a directory", []string{"-r", "build/"}, true},
+		{"Capital R", []string{"-R", "build"}, true},
+		{"Interactive on a file", []string{"-i", "notes.txt"}, true},
+		{"Force on a file", []string{"-f", "notes.txt"}, true},
+		{"Combined flags", []string{"-rf", "build", "notes.txt"}, true},
+		{"Verbose and dir", []string{"-v", "-d", "build/obj"}, true},
+		{"Long flags", []string{"--recursive", "--interactive=once", "build"}, true},
+		{"vanish extensions", []string{"-rf", "--no-hooks", "--allow-protected", "build"}, true},
+		{"Operands after --", []string{"-f", "--", "notes.txt"}, true},
+		{"Restore pattern of a missing file", []string{"-r", "report.pdf"}, false},
+		{"One operand missing", []string{"-rf", "build", "gone.txt"}, false},
+		{"No flags", []string{"notes.txt"}, false},
+		{"No operands", []string{"-v"}, false},
+		{"vx-only flag", []string{"-r", "--tag", "x", "build"}, false},
+		{"Flag after the operand", []string{"notes.txt", "-v"}, false},
+		{"Subcommand", []string{"-v", "list"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRmCommandLine(tt.args); got != tt.expected {
+				t.Errorf("IsRmCommandLine(%q) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"notes.txt"}, "delete"},
+		{[]string{"-r", "report.pdf"}, "restore"},
+		{[]string{"-i", "report.pdf"}, "info"},
+		{[]string{"-V"}, "version"},
+		{[]string{"--version"}, "version"},
+		{[]string{"-v"}, "delete"},
+		{[]string{"-v", "notes.txt"}, "delete"},
+		{[]string{"-v", "-r", "report.pdf"}, "restore"},
+		{[]string{"list", "-v"}, "list"},
+		{[]string{"restore", "--", "-r"}, "restore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			cmd, _, err := findCommand(tt.args)
+			if err != nil {
+				t.Fatalf("findCommand(%q) failed: %v", tt.args, err)
+			}
+			if cmd.Name != tt.expected {
+				t.Errorf("findCommand(%q) = %s, want %s", tt.args, cmd.Name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseArgsRmFlags(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// rm-only flags go to rm mode even when the operands do not exist, so
+	// rm reports them
+	for _, args := range [][]string{{"-rf", "gone"}, {"-R", "gone"}, {"--recursive", "gone"}} {
+		if _, _, err := parseArgs(args, types.Config{}); !errors.Is(err, errRmFlags) {
+			t.Errorf("parseArgs(%q) error = %v, want errRmFlags", args, err)
+		}
+	}
+	if _, _, err := parseArgs([]string{"-r", "gone"}, types.Config{}); err != nil {
+		t.Errorf("parseArgs(-r gone) failed: %v", err)
+	}
+}
+
+func TestRemoveOneUncommitted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	proj := filepath.Join(repo, "proj")
+	os.MkdirAll(proj, 0755)
+	os.WriteFile(filepath.Join(proj, "main.go"), []byte("v1"), 0644)
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	os.WriteFile(filepath.Join(proj, "new.go"), []byte("new"), 0644)
+
+	var cfg types.Config
+	cfg.Cache.Directory = t.TempDir()
+	cfg.Cache.Days = 30
+
+	tests := []struct {
+		name   string
+		opts   rmOptions
+		answer string
+		ok     bool
+		moved  bool
+	}{
+		{"refused", rmOptions{Recursive: true}, "", false, false},
+		{"refused with -f", rmOptions{Recursive: true, Force: true, Interactive: "never"}, "", false, false},
+		{"declined with -i", rmOptions{Recursive: true, Interactive: "always"}, "n\n", true, false},
+		{"declined with -I", rmOptions{Recursive: true, Interactive: "once"}, "n\n", true, false},
+		{"confirmed with -I", rmOptions{Recursive: true, Interactive: "once"}, "y\n", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin := bufio.NewReader(strings.NewReader(tt.answer))
+			item, ok := removeOne(proj, tt.opts, cfg, stdin)
+			if ok != tt.ok || (item.ID != "") != tt.moved {
+				t.Errorf("removeOne() = (%q, %v), want moved %v, ok %v", item.ID, ok, tt.moved, tt.ok)
+			}
+			if _, err := os.Stat(proj); (err == nil) == tt.moved {
+				t.Errorf("proj exists = %v after removeOne", err == nil)
+			}
+			if tt.moved {
+				os.Rename(item.CachePath, proj)
+			}
+		})
+	}
+
+	item, ok := removeOne(proj, rmOptions{Recursive: true, AllowUncommitted: true}, cfg, bufio.NewReader(strings.NewReader("")))
+	if !ok || item.ID == "" {
+		t.Errorf("removeOne() with --allow-uncommitted = (%q, %v), want it moved", item.ID, ok)
+	}
+}
+
+func TestCompleteWords(t *testing.T) {
+	var cfg types.Config
+	cfg.Cache.Directory = t.TempDir()
+	err := helpers.SaveIndex(types.Index{Items: []types.DeletedItem{
+		{ID: "a1b2", OriginalPath: "/home/u/report.pdf", Tags: []string{"work"}},
+	}}, cfg)
+	if err != nil {
+		t.Fatalf("SaveIndex failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		prev  []string
+		cur   string
+		files bool
+		want  []string // exact candidates, unless has is set
+		has   []string // candidates that must be among them
+	}{
+		{"commands", nil, "", true, nil, []string{"restore", "list", "themes"}},
+		{"command prefix", nil, "res", true, []string{"restore"}, nil},
+		{"global flags", nil, "--th", false, []string{"--themes"}, nil},
+		{"command flags", []string{"list"}, "--ta", false, []string{"--tag"}, nil},
+		{"item paths", []string{"restore"}, "/home/u/r", false, []string{"/home/u/report.pdf"}, nil},
+		{"item names", []string{"restore"}, "rep", false, []string{"report.pdf"}, nil},
+		{"item ids", []string{"info"}, "a1", false, []string{"a1b2"}, nil},
+		{"tag values", []string{"restore", "--tag"}, "", false, []string{"work"}, nil},
+		{"themes", []string{"themes"}, "", false, nil, []string{"default"}},
+		{"choices", []string{"completion"}, "", false, []string{"bash", "fish", "zsh"}, nil},
+		{"files", []string{"delete"}, "", true, nil, nil},
+		{"rm flags", []string{"rm"}, "--no-h", false, []string{"--no-hooks"}, nil},
+		{"rm files", []string{"rm", "-r"}, "", true, nil, nil},
+		{"after --", []string{"restore", "--"}, "--t", false, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, got := completeWords(tt.prev, tt.cur, cfg)
+			if files != tt.files {
+				t.Errorf("completeWords(%q, %q) files = %v, want %v", tt.prev, tt.cur, files, tt.files)
+			}
+			if tt.has != nil {
+				for _, want := range tt.has {
+					if !contains(got, want) {
+						t.Errorf("completeWords(%q, %q) = %q, missing %q", tt.prev, tt.cur, got, want)
+					}
+				}
+				if contains(got, "__complete") {
+					t.Errorf("completeWords(%q, %q) offers the hidden __complete", tt.prev, tt.cur)
+				}
+				return
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("completeWords(%q, %q) = %q, want %q", tt.prev, tt.cur, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	scripts := map[string]struct{ script, hook string }{
+		"bash": {bashCompletion, "complete -o filenames -F _vx_completions vx"},
+		"zsh":  {zshCompletion, "compdef _vx vx"},
+		"fish": {fishCompletion, "complete -c vx -f -a '(__vx_complete)'"},
+	}
+	for _, shell := range completionShells {
+		s, ok := scripts[shell]
+		if !ok {
+			t.Errorf("no script test for %s", shell)
+			continue
+		}
+		if !strings.Contains(s.script, "vx __complete") || !strings.Contains(s.script, s.hook) {
+			t.Errorf("%s script does not call vx __complete or register %q", shell, s.hook)
+		}
+		// Check the syntax where the shell is installed
+		if _, err := exec.LookPath(shell); err == nil {
+			cmd := exec.Command(shell, "-n")
+			cmd.Stdin = strings.NewReader(s.script)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("%s -n: %v\n%s", shell, err, out)
+			}
+		}
+	}
+	if err := ShowCompletion("tcsh"); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestGenerateDocs(t *testing.T) {
+	// The defaults in the docs depend on HOME and the XDG directories
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(name, "")
+	}
+
+	dir := t.TempDir()
+	if err := GenerateDocs(dir); err != nil {
+		t.Fatalf("GenerateDocs failed: %v", err)
+	}
+
+	reference := filepath.Join("..", "..", "docs", "reference")
+	entries, err := os.ReadDir(reference)
+	if err != nil {
+		t.Fatalf("reading %s: %v", reference, err)
+	}
+	generated, _ := os.ReadDir(dir)
+	if len(generated) != len(entries) {
+		t.Errorf("generated %d files, docs/reference holds %d", len(generated), len(entries))
+	}
+	for _, entry := range entries {
+		want, _ := os.ReadFile(filepath.Join(reference, entry.Name()))
+		got, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Errorf("%s was not generated: %v", entry.Name(), err)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("docs/reference/%s is out of date, run go generate", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"vanish/internal/config"
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// What positional arguments and flag values complete to. Anything else
+// completes to the command's Choices, or to nothing. With both, the first
+// positional argument completes to the Choices.
+const (
+	completeFiles      = "files"       // paths on disk, left to the shell
+	completeItems      = "items"       // original paths and IDs from the index
+	completeTags       = "tags"        // tags used in the index
+	completeThemes     = "themes"      // built-in theme names
+	completeCommands   = "commands"    // subcommand names
+	completeOps        = "ops"         // operations vx history filters by
+	completeConfigKeys = "config-keys" // dotted keys of the config file
+)
+
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// RunComplete implements the hidden vx __complete entry point used by the
+// completion scripts. words are the command line words after vx, the last
+// one being the word under the cursor (possibly empty). The first output
+// line is "files" or "nofiles", telling the shell whether to add its own
+// file completion; every further line is a candidate.
+func RunComplete(words []string, cfg types.Config) int {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	files, candidates := completeWords(words[:len(words)-1], words[len(words)-1], cfg)
+	if files {
+		fmt.Println("files")
+	} else {
+		fmt.Println("nofiles")
+	}
+	for _, candidate := range candidates {
+		fmt.Println(candidate)
+	}
+	return 0
+}
+
+// completeWords returns whether files should be completed and the
+// candidates for cur given the words before it.
+func completeWords(prev []string, cur string, cfg types.Config) (bool, []string) {
+	// rm mode has its own flags and only takes files
+	if len(prev) > 0 && prev[0] == "rm" {
+		if strings.HasPrefix(cur, "-") && !contains(prev, "--") {
+			var names []string
+			for _, f := range rmFlagDefs {
+				names = append(names, f.Names...)
+			}
+			return false, filterPrefix(names, cur)
+		}
+		return true, nil
+	}
+
+	cmd, rest, err := findCommand(prev)
+	if err != nil {
+		return false, nil
+	}
+	flagsDone := contains(rest, "--")
+
+	// Value for the flag right before the cursor
+	if n := len(rest); n > 0 && !flagsDone {
+		if f := cmd.lookupFlag(rest[n-1]); f != nil && f.Value != "" {
+			return f.Complete == completeFiles, filterPrefix(completeValues(f.Complete, nil, cfg), cur)
+		}
+	}
+
+	if strings.HasPrefix(cur, "-") && !flagsDone {
+		var names []string
+		for _, set := range [][]flagDef{cmd.Flags, globalFlags} {
+			for _, f := range set {
+				names = append(names, f.Names...)
+			}
+		}
+		if cmd.Implicit {
+			// Legacy command flags are still valid before a command is chosen
+			for _, c := range cliCommands {
+				if !c.Hidden {
+					names = append(names, c.Aliases...)
+				}
+			}
+		}
+		return false, filterPrefix(names, cur)
+	}
+
+	given := countPositional(cmd, rest)
+	if cmd.MaxArgs >= 0 && given >= cmd.MaxArgs {
+		return false, nil
+	}
+
+	candidates := completeValues(cmd.Complete, cmd.Choices, cfg)
+	if len(cmd.Choices) > 0 && cmd.Complete != "" && given == 0 {
+		candidates = cmd.Choices
+	}
+	if cmd.Implicit && given == 0 && !flagsDone {
+		candidates = append(candidates, completeValues(completeCommands, nil, cfg)...)
+	}
+	return cmd.Complete == completeFiles, filterPrefix(candidates, cur)
+}
+
+// completeValues lists every value of the given kind.
+func completeValues(kind string, choices []string, cfg types.Config) []string {
+	var values []string
+
+	switch kind {
+	case completeItems, completeTags:
+		index, err := helpers.LoadIndex(cfg)
+		if err != nil {
+			return nil
+		}
+		for _, item := range index.Items {
+			if kind == completeTags {
+				values = append(values, item.Tags...)
+				continue
+			}
+			values = append(values, item.OriginalPath, filepath.Base(item.OriginalPath), item.ID)
+		}
+	case completeThemes:
+		for name := range config.GetDefaultThemes() {
+			values = append(values, name)
+		}
+	case completeOps:
+		for _, op := range helpers.HistoryOperations {
+			values = append(values, strings.ToLower(op))
+		}
+	case completeConfigKeys:
+		for _, field := range config.Schema() {
+			values = append(values, field.Key)
+		}
+	case completeCommands:
+		for _, c := range cliCommands {
+			if !c.Implicit && !c.Hidden {
+				values = append(values, c.Name)
+			}
+		}
+	default:
+		values = append(values, choices...)
+	}
+
+	return values
+}
+
+// countPositional counts the positional arguments already given to cmd,
+// skipping flags and their values.
+func countPositional(cmd *commandDef, rest []string) int {
+	count := 0
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if arg == "--" {
+			return count + len(rest) - i - 1
+		}
+		if !isFlag(arg) {
+			count++
+			continue
+		}
+		if f := cmd.lookupFlag(arg); f != nil && f.Value != "" {
+			i++
+		}
+	}
+	return count
+}
+
+// filterPrefix returns the sorted, de-duplicated values starting with prefix.
+func filterPrefix(values []string, prefix string) []string {
+	seen := make(map[string]bool)
+	var matches []string
+	for _, v := range values {
+		if v == "" || seen[v] || !strings.HasPrefix(v, prefix) {
+			continue
+		}
+		seen[v] = true
+		matches = append(matches, v)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ShowCompletion prints the completion script for the given shell.
+func ShowCompletion(shell string) error {
+	switch shell {
+	case "bash":
+		os.Stdout.WriteString(bashCompletion)
+	case "zsh":
+		os.Stdout.WriteString(zshCompletion)
+	case "fish":
+		os.Stdout.WriteString(fishCompletion)
+	default:
+		return fmt.Errorf("unsupported shell '%s', use one of: %s", shell, strings.Join(completionShells, ", "))
+	}
+	return nil
+}
+
+// The scripts only collect the words and hand them to vx __complete, so
+// commands, flags and live cache data are always in sync with the binary.
+
+const bashCompletion = `# bash completion for vx
+# Load with: source <(vx completion bash)
+
+_vx_completions() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    local -a out
+    out=($(vx __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    COMPREPLY=("${out[@]:1}")
+    if [[ "${out[0]}" == "files" ]]; then
+        COMPREPLY+=($(compgen -f -- "$cur"))
+    fi
+}
+
+complete -o filenames -F _vx_completions vx
+`
+
+const zshCompletion = `#compdef vx
+# zsh completion for vx
+# Load with: source <(vx completion zsh), or save as _vx in your $fpath
+
+_vx() {
+    local -a out candidates
+    out=("${(@f)$(vx __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    candidates=("${(@)out[2,-1]}")
+    if (( ${#candidates} )); then
+        compadd -- "${candidates[@]}"
+    fi
+    if [[ "${out[1]}" == "files" ]]; then
+        _files
+    fi
+}
+
+if [[ "${funcstack[1]}" == "_vx" ]]; then
+    _vx "$@"
+else
+    compdef _vx vx
+fi
+`
+
+const fishCompletion = `# fish completion for vx
+# Load with: vx completion fish | source
+
+function __vx_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    set -l cur (commandline -ct)
+    set -l out (vx __complete $args "$cur" 2>/dev/null)
+    test (count $out) -gt 0; or return
+    if test "$out[1]" = files
+        __fish_complete_path "$cur"
+    end
+    set -e out[1]
+    printf '%s\n' $out
+end
+
+complete -c vx -f -a '(__vx_complete)'
+`
@@ -0,0 +1,445 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/term"
+
+	"vanish/internal/config"
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// configAction is a word vx config takes, with its arguments.
+type configAction struct {
+	args     string // synopsis for errors, e.g. "<key> <value>"
+	min, max int
+}
+
+var configActions = map[string]configAction{
+	"path":     {},
+	"check":    {},
+	"get":      {"<key>", 1, 1},
+	"set":      {"<key> <value>", 2, 2},
+	"edit":     {},
+	"show":     {},
+	"export":   {"[<file>]", 0, 1},
+	"import":   {"<file>", 1, 1},
+	"backups":  {},
+	"rollback": {"[<backup>]", 0, 1},
+}
+
+// validateConfigArgs checks the action, its arguments and that the flags
+// given apply to it.
+func validateConfigArgs(p ParsedArgs) error {
+	if len(p.Filenames) == 0 {
+		return nil
+	}
+	name := p.Filenames[0]
+	action, ok := configActions[name]
+	given := len(p.Filenames) - 1
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown config action '%s'", name)
+	case given < action.min:
+		return fmt.Errorf("config %s requires %s", name, action.args)
+	case given > action.max && action.max == 0:
+		return fmt.Errorf("config %s takes no arguments", name)
+	case given > action.max:
+		return fmt.Errorf("config %s takes %s", name, action.args)
+	case p.Effective && name != "show":
+		return fmt.Errorf("--effective only applies to config show")
+	case p.WithTheme && name != "export":
+		return fmt.Errorf("--with-theme only applies to config export")
+	case (p.NoConfirm || p.DryRun) && name != "import" && name != "rollback":
+		return fmt.Errorf("-f and --dry-run only apply to config import and rollback")
+	}
+	return nil
+}
+
+// RunConfig runs a vx config action, printing the config file path
+// without one.
+func RunConfig(p ParsedArgs) error {
+	action := "path"
+	if len(p.Filenames) > 0 {
+		action = p.Filenames[0]
+	}
+	arg := func(i int) string {
+		if i < len(p.Filenames) {
+			return p.Filenames[i]
+		}
+		return ""
+	}
+
+	switch action {
+	case "check":
+		return CheckConfigFile()
+	case "get":
+		return GetConfigValue(arg(1))
+	case "set":
+		return SetConfigValue(arg(1), arg(2))
+	case "edit":
+		return EditConfig()
+	case "show":
+		return ShowConfig(p.Effective)
+	case "export":
+		return ExportConfig(arg(1), p.WithTheme)
+	case "import":
+		return ImportConfig(arg(1), p.Options())
+	case "backups":
+		return ShowConfigBackups()
+	case "rollback":
+		return RollbackConfig(arg(1), p.Options())
+	}
+	fmt.Println(helpers.GetConfigPath())
+	return nil
+}
+
+// CheckConfigFile prints every problem in the config file in file order,
+// errors marked ✗ and warnings ⚠. It fails when there are errors, warnings
+// alone leave the file valid.
+func CheckConfigFile() error {
+	path := helpers.GetConfigPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if os.Getenv(helpers.ConfigEnv) != "" {
+			return fmt.Errorf("%s is set to %s, which does not exist", helpers.ConfigEnv, path)
+		}
+		fmt.Printf("No config file at %s, the defaults are used\n", path)
+		return nil
+	}
+
+	problems, err := config.CheckConfig(path)
+	if err != nil {
+		return fmt.Errorf("error checking config: %w", err)
+	}
+
+	errorCount, warningCount := printProblems(problems)
+	if errorCount > 0 {
+		return fmt.Errorf("%s has %d error(s) and %d warning(s)", path, errorCount, warningCount)
+	}
+	if warningCount > 0 {
+		fmt.Printf("✓ %s is valid, with %d warning(s)\n", path, warningCount)
+		return nil
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+	return nil
+}
+
+// printProblems prints problems marked ✗ for errors and ⚠ for warnings
+// and counts them.
+func printProblems(problems []config.Problem) (errorCount, warningCount int) {
+	for _, p := range problems {
+		if p.Warning {
+			warningCount++
+			fmt.Printf("⚠ %s\n", p)
+		} else {
+			errorCount++
+			fmt.Printf("✗ %s\n", p)
+		}
+	}
+	return errorCount, warningCount
+}
+
+// GetConfigValue prints the value vanish uses for key: strings as they
+// are, lists one entry per line.
+func GetConfigValue(key string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	value, err := config.GetValue(cfg, key)
+	if err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case []string:
+		for _, s := range v {
+			fmt.Println(s)
+		}
+	default:
+		fmt.Println(v)
+	}
+	return nil
+}
+
+// SetConfigValue sets key in the config file, keeping its comments and
+// layout. A value that would make the file invalid is refused.
+func SetConfigValue(key, value string) error {
+	path := helpers.GetConfigPath()
+	problems, err := config.SetValue(path, key, value)
+	printProblems(problems)
+	var invalid *config.InvalidConfigError
+	if errors.As(err, &invalid) {
+		return fmt.Errorf("%s not changed, %s would be invalid", key, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	literal, _ := config.ParseValue(key, value)
+	fmt.Printf("✓ Set %s = %s in %s\n", key, literal, path)
+	return nil
+}
+
+// EditConfig opens a copy of the config file in $VISUAL or $EDITOR (vi
+// without either) and saves it once it has no errors. While it has, the
+// problems are shown and the editor is opened again, or the changes are
+// discarded.
+func EditConfig() error {
+	path := helpers.GetConfigPath()
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vanish-edit-*.toml")
+	if err != nil {
+		return fmt.Errorf("failed to create a copy to edit: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create a copy to edit: %w", err)
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			return err
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes")
+			return nil
+		}
+
+		problems, err := config.CheckConfigData(path, edited)
+		if err != nil {
+			return err
+		}
+		errorCount, _ := printProblems(problems)
+		if errorCount == 0 {
+			if err := config.WriteConfig(path, edited); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Saved %s\n", path)
+			return nil
+		}
+
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !confirmConfig(stdin, "Edit again?", true) {
+			return fmt.Errorf("changes discarded, the edited config has %d error(s)", errorCount)
+		}
+	}
+}
+
+// confirmConfig asks a yes/no question on stderr, an empty answer counts
+// as def.
+func confirmConfig(stdin *bufio.Reader, question string, def bool) bool {
+	if def {
+		fmt.Fprintf(os.Stderr, "%s [Y/n] ", question)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	}
+	answer, _ := stdin.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// runEditor opens path in the user's editor. The editor setting may carry
+// arguments, e.g. "code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
+
+// ShowConfig prints the config file, or with effective the configuration
+// vanish runs with: the file merged over the defaults, with the theme
+// colors applied.
+func ShowConfig(effective bool) error {
+	if !effective {
+		data, err := os.ReadFile(helpers.GetConfigPath())
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	encoder := toml.NewEncoder(os.Stdout)
+	encoder.Indent = ""
+	return encoder.Encode(cfg)
+}
+
+// ExportConfig writes the config file to file, or into file/vanish.toml
+// when it is a directory or ends in a slash, or to stdout without one. See
+// config.ExportConfig for withTheme.
+func ExportConfig(file string, withTheme bool) error {
+	data, err := config.ExportConfig(withTheme)
+	if err != nil {
+		return err
+	}
+	if file == "" || file == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if info, err := os.Stat(file); (err == nil && info.IsDir()) || strings.HasSuffix(file, "/") {
+		file = filepath.Join(file, "vanish.toml")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to export config: %w", err)
+	}
+	fmt.Printf("✓ Exported %s to %s\n", helpers.GetConfigPath(), file)
+	return nil
+}
+
+// ImportConfig replaces the config file with source once it is valid,
+// after showing which settings change and asking. The current file is
+// backed up first.
+func ImportConfig(source string, opts types.Options) error {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	return replaceConfig(data, source, opts)
+}
+
+// RollbackConfig puts a backup of the config file back, the newest one
+// without a name, the same way ImportConfig imports a file. The current
+// file is backed up too, so a rollback can be rolled back.
+func RollbackConfig(name string, opts types.Options) error {
+	backup, err := config.FindBackup(helpers.GetConfigPath(), name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", backup, err)
+	}
+	return replaceConfig(data, backup, opts)
+}
+
+// replaceConfig checks data read from source, shows how the settings
+// differ from the current config file and replaces it, backing it up
+// first. opts.DryRun only shows the difference, opts.NoConfirm skips the
+// question.
+func replaceConfig(data []byte, source string, opts types.Options) error {
+	path := helpers.GetConfigPath()
+	problems, err := config.CheckConfigData(source, data)
+	if err != nil {
+		return err
+	}
+	if errorCount, _ := printProblems(problems); errorCount > 0 {
+		return fmt.Errorf("%s has %d error(s), the config was not changed", source, errorCount)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bytes.Equal(current, data) {
+		fmt.Printf("%s is the same as %s, nothing to do\n", source, path)
+		return nil
+	}
+
+	changes := config.DiffSettings(current, data)
+	if len(changes) == 0 {
+		fmt.Println("No settings change, only comments or layout")
+	} else {
+		fmt.Printf("Settings changed by %s:\n", source)
+		for _, c := range changes {
+			fmt.Printf("  %-28s %s → %s\n", c.Key, c.Old, c.New)
+		}
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	if !opts.NoConfirm {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("not replacing %s without confirmation, pass -f", path)
+		}
+		if !confirmConfig(bufio.NewReader(os.Stdin), "Replace "+path+"?", false) {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	backup, err := config.BackupConfig(path)
+	if err != nil {
+		return err
+	}
+	if backup != "" {
+		fmt.Printf("Backed up the current config to %s\n", backup)
+	}
+	if err := config.WriteConfig(path, data); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Replaced %s with %s\n", path, source)
+	return nil
+}
+
+// ShowConfigBackups lists the backups of the config file, newest first.
+func ShowConfigBackups() error {
+	backups, err := config.ConfigBackups(helpers.GetConfigPath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups yet, vx config import and rollback make them")
+		return nil
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		taken := "unknown time       "
+		if t, err := config.BackupTime(backups[i]); err == nil {
+			taken = t.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s  %s\n", taken, backups[i])
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vanish/internal/config"
+	"vanish/internal/helpers"
+)
+
+// GenerateDocs writes the vx(1) man page, the markdown command reference and
+// the config reference into dir. Everything is generated from the command
+// definitions in cli.go and the fields of types.Config, so the docs cannot
+// drift from --help and the parser.
+func GenerateDocs(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+
+	files := []struct{ name, content string }{
+		{"vx.1", manPage()},
+		{"commands.md", commandsMarkdown()},
+		{"config.md", configMarkdown()},
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		fmt.Println("Wrote", path)
+	}
+	return nil
+}
+
+// visibleCommands returns the named commands shown in help and docs.
+func visibleCommands() []*commandDef {
+	var cmds []*commandDef
+	for i := range cliCommands {
+		if !cliCommands[i].Implicit && !cliCommands[i].Hidden {
+			cmds = append(cmds, &cliCommands[i])
+		}
+	}
+	return cmds
+}
+
+func flagSpelling(f flagDef) string {
+	s := strings.Join(f.Names, ", ")
+	if f.Value != "" {
+		if strings.HasPrefix(f.Value, "[") {
+			s += f.Value
+		} else {
+			s += " " + f.Value
+		}
+	}
+	return s
+}
+
+func commandSynopsis(cmd *commandDef) string {
+	s := "vx " + cmd.Name
+	if len(cmd.Flags) > 0 {
+		s += " [flags]"
+	}
+	if cmd.Args != "" {
+		s += " " + cmd.Args
+	}
+	return s
+}
+
+// --- Man page ---
+
+// roff escapes text for use in a man page.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func manFlags(b *strings.Builder, flags []flagDef) {
+	for _, f := range flags {
+		fmt.Fprintf(b, ".TP\n.B %s\n%s\n", roff(flagSpelling(f)), roff(f.Help))
+	}
+}
+
+func manPage() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".\\\" Generated by vx --gen-docs. Do not edit.\n")
+	fmt.Fprintf(&b, ".TH VX 1 \"\" \"vanish %s\" \"User Commands\"\n", VERSION)
+
+	b.WriteString(".SH NAME\nvx \\- safe file and directory removal with recovery\n")
+
+	b.WriteString(".SH SYNOPSIS\n")
+	b.WriteString(".B vx\n[\\fIflags\\fR] [\\fB\\-\\-\\fR] \\fIfiles\\fR...\n.br\n")
+	b.WriteString(".B vx\n\\fIcommand\\fR [\\fIflags\\fR] [\\fIargs\\fR...]\n")
+
+	b.WriteString(".SH DESCRIPTION\n")
+	b.WriteString("vx moves files and directories into a cache instead of deleting them, ")
+	b.WriteString("so they can be restored until the retention period runs out. ")
+	b.WriteString("Commands may also be given with their legacy flag spelling, ")
+	b.WriteString("flags may appear anywhere before \\fB\\-\\-\\fR, and everything after ")
+	b.WriteString("\\fB\\-\\-\\fR is a file name.\n")
+
+	b.WriteString(".SH COMMANDS\n")
+	for _, cmd := range visibleCommands() {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roff(commandSynopsis(cmd)), roff(cmd.Summary))
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(&b, ".br\nAlso available as \\fB%s\\fR.\n", roff(strings.Join(cmd.Aliases, ", ")))
+		}
+		if len(cmd.Choices) > 0 {
+			fmt.Fprintf(&b, ".br\nOne of: %s.\n", roff(strings.Join(cmd.Choices, ", ")))
+		}
+		if len(cmd.Flags) > 0 {
+			b.WriteString(".RS\n")
+			manFlags(&b, cmd.Flags)
+			b.WriteString(".RE\n")
+		}
+	}
+
+	b.WriteString(".SH DELETE FLAGS\n")
+	manFlags(&b, cliCommands[0].Flags)
+
+	b.WriteString(".SH GLOBAL FLAGS\n")
+	manFlags(&b, globalFlags)
+
+	b.WriteString(".SH RM COMPATIBILITY\n")
+	b.WriteString("\\fBvx rm\\fR, or vx invoked as \\fBrm\\fR, accepts rm's options with rm's messages and exit codes:\n")
+	manFlags(&b, rmFlagDefs)
+	fmt.Fprintf(&b, ".PP\n%s\n", roff(strings.ReplaceAll(rmNotes, "\n", " ")))
+
+	b.WriteString(".SH CONFIGURATION\n")
+	fmt.Fprintf(&b, "Settings are read from \\fI%s\\fR, \\fI%s\\fR without XDG_CONFIG_HOME.\n", roff("$XDG_CONFIG_HOME/vanish/vanish.toml"), roff("~/.config/vanish/vanish.toml"))
+	for _, field := range config.Schema() {
+		fmt.Fprintf(&b, ".TP\n.B %s\n(%s, default \\fB%s\\fR, \\fB%s\\fR) %s\n",
+			roff(field.Key), field.Type, roff(config.FormatValue(field.Default)),
+			roff(strings.Join(config.EnvNames(field.Key), ", ")), roff(field.Doc))
+	}
+
+	b.WriteString(".SH ENVIRONMENT\n")
+	fmt.Fprintf(&b, ".TP\n.B %s\nRead this config file instead of \\fIvanish.toml\\fR. It must exist.\n", helpers.ConfigEnv)
+	b.WriteString(".TP\n.B XDG_CONFIG_HOME, XDG_DATA_HOME, XDG_STATE_HOME\nWhere the config file, the deleted files and the logs are kept, see FILES.\n")
+	b.WriteString(".TP\n.B VANISH_<TABLE>_<KEY>\n")
+	b.WriteString("Override a setting for this run, e.g. \\fBVANISH_CACHE_DAYS=3\\fR for cache.days. ")
+	b.WriteString("The short names are listed in CONFIGURATION. \\fB\\-\\-set\\fR wins over the environment, the environment over the file.\n")
+
+	b.WriteString(".SH FILES\n")
+	b.WriteString(".TP\n.I ~/.config/vanish/vanish.toml\nConfiguration file, in $XDG_CONFIG_HOME/vanish when it is set.\n")
+	b.WriteString(".TP\n.I ~/.config/vanish/ignore\nGlobal ignore rules in gitignore syntax.\n")
+	b.WriteString(".TP\n.I .vanishignore\nPer-directory ignore rules. Matching paths are refused, and left in place when their parent directory is deleted.\n")
+	b.WriteString(".TP\n.I ~/.local/share/vanish/\nDefault cache directory, holding the deleted items and \\fIindex.json\\fR, in $XDG_DATA_HOME/vanish when it is set. Earlier versions used ~/.cache/vanish, which is moved here once.\n")
+	b.WriteString(".TP\n.I ~/.local/state/vanish/vanish.log\nOperation log, in $XDG_STATE_HOME/vanish when it is set, one JSON object per line unless logging.format is text. Rotated logs are kept next to it as vanish-<time>.log, gzipped when logging.compress is set. With logging.audit, vanish.log.chain holds the last record of the hash chain.\n")
+
+	b.WriteString(".SH EXAMPLES\n")
+	for _, ex := range usageExamples {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roff(ex[0]), roff(strings.TrimPrefix(ex[1], "# ")))
+	}
+
+	b.WriteString(".SH SEE ALSO\n.BR rm (1)\n")
+	return b.String()
+}
+
+// --- Markdown ---
+
+// mdCell escapes text for a markdown table cell.
+func mdCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func mdFlags(b *strings.Builder, flags []flagDef) {
+	b.WriteString("| Flag | Description |\n|------|-------------|\n")
+	for _, f := range flags {
+		fmt.Fprintf(b, "| `%s` | %s |\n", mdCell(flagSpelling(f)), mdCell(f.Help))
+	}
+	b.WriteString("\n")
+}
+
+func commandsMarkdown() string {
+	var b strings.Builder
+
+	b.WriteString("<!-- Generated by vx --gen-docs. Do not edit. -->\n\n")
+	b.WriteString("# vx command reference\n\n")
+	b.WriteString("```\nvx [flags] [--] <files...>\nvx <command> [flags] [args...]\n```\n\n")
+	b.WriteString("Commands may also be given with their legacy flag spelling. Flags may appear anywhere before `--`, ")
+	b.WriteString("and everything after `--` is a file name, so `vx -- list` deletes a file called `list`.\n\n")
+
+	b.WriteString("## Commands\n\n| Command | Legacy flags | Description |\n|---------|--------------|-------------|\n")
+	b.WriteString("| `vx <files...>` | — | " + mdCell(cliCommands[0].Summary) + " |\n")
+	for _, cmd := range visibleCommands() {
+		aliases := "—"
+		if len(cmd.Aliases) > 0 {
+			aliases = "`" + strings.Join(cmd.Aliases, "`, `") + "`"
+		}
+		usage := strings.TrimSpace("vx " + cmd.Name + " " + cmd.Args)
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", mdCell(usage), aliases, mdCell(cmd.Summary))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Deleting files\n\n`vx [flags] <files...>`\n\n")
+	mdFlags(&b, cliCommands[0].Flags)
+
+	for _, cmd := range visibleCommands() {
+		fmt.Fprintf(&b, "## vx %s\n\n%s.\n\n```\n%s\n```\n\n", cmd.Name, cmd.Summary, commandSynopsis(cmd))
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(&b, "Also available as `%s`.\n\n", strings.Join(cmd.Aliases, "`, `"))
+		}
+		if len(cmd.Choices) > 0 {
+			fmt.Fprintf(&b, "One of: `%s`.\n\n", strings.Join(cmd.Choices, "`, `"))
+		}
+		switch {
+		case cmd.Name == "rm":
+			b.WriteString("Accepts rm's options with rm's messages and exit codes, and is used automatically when vx is invoked as `rm`.\n\n")
+			mdFlags(&b, rmFlagDefs)
+			b.WriteString(strings.ReplaceAll(rmNotes, "\n", " ") + "\n\n")
+		case len(cmd.Flags) > 0:
+			mdFlags(&b, cmd.Flags)
+		}
+	}
+
+	b.WriteString("## Global flags\n\n")
+	mdFlags(&b, globalFlags)
+
+	b.WriteString("## Examples\n\n```bash\n")
+	for _, ex := range usageExamples {
+		fmt.Fprintf(&b, "%-44s %s\n", ex[0], ex[1])
+	}
+	b.WriteString("```\n")
+
+	return b.String()
+}
+
+func configMarkdown() string {
+	var b strings.Builder
+
+	b.WriteString("<!-- Generated by vx --gen-docs. Do not edit. -->\n\n")
+	b.WriteString("# vx configuration reference\n\n")
+	b.WriteString("Settings are read from `$XDG_CONFIG_HOME/vanish/vanish.toml`, `~/.config/vanish/vanish.toml` without `XDG_CONFIG_HOME`. ")
+	b.WriteString("Keys are shown as `section.key`; `cache.days` is written as `days = 10` under `[cache]`.\n\n")
+	b.WriteString("`VANISH_CONFIG` names another file to read. Each setting can also be given for one run by the environment variables listed, ")
+	b.WriteString("or by `--set key=value`. `--set` wins over the environment, the environment over the file, and the file over the defaults.\n\n")
+	b.WriteString("| Key | Type | Default | Environment | Description |\n|-----|------|---------|-------------|-------------|\n")
+	for _, field := range config.Schema() {
+		fmt.Fprintf(&b, "| `%s` | %s | `%s` | `%s` | %s |\n",
+			field.Key, field.Type, mdCell(config.FormatValue(field.Default)),
+			strings.Join(config.EnvNames(field.Key), "`, `"), mdCell(field.Doc))
+	}
+	return b.String()
+}
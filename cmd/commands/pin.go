@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"fmt"
+
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// PinItems pins (or unpins) every cached item matching one of the patterns.
+// Pinned items are skipped by auto-cleanup and --purge.
+func PinItems(patterns []string, pinned bool, config types.Config) error {
+	changed, err := helpers.SetPinned(patterns, pinned, config)
+	if err != nil {
+		return fmt.Errorf("error updating index: %v", err)
+	}
+
+	verb, action := "pin", "Pinned"
+	if !pinned {
+		verb, action = "unpin", "Unpinned"
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("No items to %s matched %v\n", verb, patterns)
+		return nil
+	}
+
+	for _, item := range changed {
+		fmt.Printf("%s: %s (%s)\n", action, item.OriginalPath, item.ID)
+	}
+	fmt.Printf("%s %d item(s)\n", action, len(changed))
+	return nil
+}
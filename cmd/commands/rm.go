@@ -0,0 +1,417 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// rmOptions holds the rm flags understood by the compatibility mode.
+type rmOptions struct {
+	Recursive        bool
+	Force            bool
+	Dir              bool
+	Verbose          bool
+	OneFileSystem    bool
+	PreserveRoot     bool
+	Interactive      string // "never", "once" or "always"; the last of -f/-i/-I wins
+	AllowProtected   bool   // vanish extension, same as vx --allow-protected
+	NoHooks          bool   // vanish extension, same as vx --no-hooks
+	AllowUncommitted bool   // vanish extension, same as vx --allow-uncommitted
+}
+
+// rmFlagDefs documents the options parseRmArgs understands. They are used
+// for help, completion and the generated docs; parsing itself follows rm's
+// rules (combined short flags, last of -f/-i/-I wins) in parseRmArgs.
+var rmFlagDefs = []flagDef{
+	{Names: []string{"-f", "--force"}, Help: "Ignore nonexistent files and arguments, never prompt"},
+	{Names: []string{"-i"}, Help: "Prompt before every removal"},
+	{Names: []string{"-I"}, Help: "Prompt once before removing more than three files, or when removing recursively"},
+	{Names: []string{"--interactive"}, Value: "[=WHEN]", Help: "Prompt according to WHEN: never, once (-I) or always (-i); without WHEN, always"},
+	{Names: []string{"--one-file-system"}, Help: "When removing recursively, skip any argument containing a directory on a different file system"},
+	{Names: []string{"--no-preserve-root"}, Help: "Do not treat '/' specially"},
+	{Names: []string{"--preserve-root"}, Help: "Do not remove '/' (default)"},
+	{Names: []string{"-r", "-R", "--recursive"}, Help: "Remove directories and their contents"},
+	{Names: []string{"-d", "--dir"}, Help: "Remove empty directories"},
+	{Names: []string{"-v", "--verbose"}, Help: "Explain what is being done"},
+	{Names: []string{"--allow-protected"}, Help: "Allow removing paths vanish protects"},
+	{Names: []string{"--allow-uncommitted"}, Help: "Allow removing files git has not committed"},
+	{Names: []string{"--no-hooks"}, Help: "Don't run the [hooks] commands"},
+	{Names: []string{"--help"}, Help: "Show this help"},
+	{Names: []string{"--version"}, Help: "Show version information"},
+}
+
+// rmNotes closes the rm help and the rm section of the generated docs.
+const rmNotes = "Removed files can be brought back with 'vx restore'. The vanish cache\nand '/' are never removed, even with --no-preserve-root."
+
+// rmLongFlags are the long options that switch ParseArgs into rm mode.
+var rmLongFlags = map[string]bool{
+	"--recursive":        true,
+	"--force":            true,
+	"--dir":              true,
+	"--verbose":          true,
+	"--one-file-system":  true,
+	"--preserve-root":    true,
+	"--no-preserve-root": true,
+	"--interactive":      true,
+}
+
+// rmExtensionFlags are the vanish options rm mode accepts besides rm's.
+var rmExtensionFlags = map[string]bool{
+	"--allow-protected":   true,
+	"--allow-uncommitted": true,
+	"--no-hooks":          true,
+}
+
+// IsRmFlag reports whether arg is an rm option that vanish itself does not
+// understand, such as -rf or --recursive.
+func IsRmFlag(arg string) bool {
+	if strings.HasPrefix(arg, "--") {
+		name, _, _ := strings.Cut(arg, "=")
+		return rmLongFlags[name]
+	}
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	return strings.Trim(arg[1:], "rRfiIdv") == ""
+}
+
+// IsRmCommandLine reports whether args read as an rm command line rather
+// than a vx one, as they do through alias rm=vx: rm flags first, then one
+// or more operands that all exist. Otherwise -r, -i, -f and -v keep their vx
+// meanings, so "rm -r build/" would restore instead of delete. A flag after
+// an operand, as in "vx list -v", is vx's, as rm users put flags first.
+func IsRmCommandLine(args []string) bool {
+	flags, operands := 0, 0
+	for i, arg := range args {
+		if arg == "--" {
+			for _, operand := range args[i+1:] {
+				if _, err := os.Lstat(operand); err != nil {
+					return false
+				}
+				operands++
+			}
+			break
+		}
+		if isFlag(arg) {
+			if operands > 0 || !(IsRmFlag(arg) || rmExtensionFlags[arg]) {
+				return false
+			}
+			flags++
+			continue
+		}
+		if _, err := os.Lstat(arg); err != nil {
+			return false
+		}
+		operands++
+	}
+	return flags > 0 && operands > 0
+}
+
+// RunRm deletes files with rm's flags, messages and exit codes, moving them
+// into the vanish cache instead of unlinking them. It returns the exit code.
+func RunRm(args []string, cfg types.Config) int {
+	opts, files, err := parseRmArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rm: %v\nTry 'rm --help' for more information.\n", err)
+		return 1
+	}
+
+	if len(files) == 0 {
+		if opts.Force {
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, "rm: missing operand\nTry 'rm --help' for more information.")
+		return 1
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+
+	if opts.Interactive == "once" && (len(files) > 3 || opts.Recursive) {
+		question := fmt.Sprintf("rm: remove %d argument", len(files))
+		if len(files) != 1 {
+			question += "s"
+		}
+		if opts.Recursive {
+			question += " recursively"
+		}
+		if !rmPrompt(stdin, question+"? ") {
+			return 0
+		}
+	}
+
+	// The hooks see the operands that exist, like the ones of a headless delete
+	hookOpts := types.Options{AllowProtected: opts.AllowProtected, NoHooks: opts.NoHooks}
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Lstat(file); err == nil {
+			existing = append(existing, file)
+		}
+	}
+	if len(existing) > 0 {
+		if err := helpers.RunHook(helpers.HookPreDelete, existing, nil, cfg, hookOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "rm: cancelled by hook: %v\n", err)
+			return 1
+		}
+	}
+
+	exitCode := 0
+	var moved []types.DeletedItem
+	for _, file := range files {
+		item, ok := removeOne(file, opts, cfg, stdin)
+		if !ok {
+			exitCode = 1
+			continue
+		}
+		if item.ID != "" {
+			moved = append(moved, item)
+		}
+	}
+
+	if len(moved) > 0 {
+		// Keep the cache bounded like every other delete does
+		helpers.CleanupExpired(cfg)
+		if err := helpers.RunHook(helpers.HookPostDelete, nil, moved, cfg, hookOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "rm: warning: %v\n", err)
+		}
+	}
+
+	return exitCode
+}
+
+// removeOne moves a single operand to the cache and returns its item, empty
+// when nothing was moved. ok is false if the operand counts as a failure for
+// the exit code. Declined prompts and files missing under -f are not
+// failures.
+func removeOne(file string, opts rmOptions, cfg types.Config, stdin *bufio.Reader) (item types.DeletedItem, ok bool) {
+	if base := filepath.Base(filepath.Clean(file)); base == "." || base == ".." {
+		fmt.Fprintf(os.Stderr, "rm: refusing to remove '.' or '..' directory: skipping '%s'\n", file)
+		return item, false
+	}
+
+	stat, err := os.Lstat(file)
+	if err != nil {
+		if opts.Force && errors.Is(err, fs.ErrNotExist) {
+			return item, true
+		}
+		fmt.Fprintf(os.Stderr, "rm: cannot remove '%s': %s\n", file, rmErrText(err))
+		return item, false
+	}
+
+	isDir := stat.IsDir()
+	if isDir && !opts.Recursive {
+		if !opts.Dir {
+			fmt.Fprintf(os.Stderr, "rm: cannot remove '%s': Is a directory\n", file)
+			return item, false
+		}
+		if entries, err := os.ReadDir(file); err != nil || len(entries) > 0 {
+			fmt.Fprintf(os.Stderr, "rm: cannot remove '%s': Directory not empty\n", file)
+			return item, false
+		}
+	}
+
+	if absPath, err := filepath.Abs(file); err == nil && absPath == "/" && opts.Recursive && opts.PreserveRoot {
+		fmt.Fprintln(os.Stderr, "rm: it is dangerous to operate recursively on '/'")
+		fmt.Fprintln(os.Stderr, "rm: use --no-preserve-root to override this failsafe")
+		return item, false
+	}
+
+	// The whole tree moves at once, so a foreign mount anywhere inside means
+	// the argument is skipped rather than partially removed
+	if isDir && opts.Recursive && opts.OneFileSystem {
+		if other := helpers.FindOtherDevice(file); other != "" {
+			fmt.Fprintf(os.Stderr, "rm: skipping '%s', since it's on a different device\n", other)
+			return item, false
+		}
+	}
+
+	if protection := helpers.CheckProtected(file, cfg); protection.IsRefused(opts.AllowProtected) {
+		fmt.Fprintf(os.Stderr, "rm: cannot remove '%s': protected by vanish (%s)\n", file, protection.Reason)
+		return item, false
+	}
+
+	ask := opts.Interactive == "always"
+	question := fmt.Sprintf("rm: remove %s '%s'? ", rmFileKind(stat), file)
+
+	// Uncommitted git changes are refused like in a headless delete, unless
+	// -i or -I lets the user confirm them by name
+	if git := helpers.CheckGit(file); git != nil && len(git.Changes) > 0 && !opts.AllowUncommitted {
+		if opts.Interactive != "always" && opts.Interactive != "once" {
+			fmt.Fprintf(os.Stderr, "rm: cannot remove '%s': %d file(s) not committed to git (use --allow-uncommitted to override):\n", file, len(git.Changes))
+			printGitChanges(git.Changes)
+			return item, false
+		}
+		fmt.Fprintf(os.Stderr, "rm: '%s' holds %d file(s) not committed to git:\n", file, len(git.Changes))
+		printGitChanges(git.Changes)
+		ask, question = true, fmt.Sprintf("rm: remove %s '%s' and its uncommitted changes? ", rmFileKind(stat), file)
+	}
+
+	if ask && !rmPrompt(stdin, question) {
+		return item, true
+	}
+
+	item, err = helpers.MoveToCache(file, cfg, types.Options{AllowProtected: opts.AllowProtected, NoHooks: opts.NoHooks})
+	if err != nil {
+		if item.ID == "" {
+			fmt.Fprintf(os.Stderr, "rm: cannot remove '%s': %v\n", file, err)
+			return item, false
+		}
+		// The item reached the cache but the index could not be updated
+		fmt.Fprintf(os.Stderr, "rm: warning: '%s': %v\n", file, err)
+	}
+
+	if opts.Verbose {
+		if isDir {
+			fmt.Printf("removed directory '%s'\n", file)
+		} else {
+			fmt.Printf("removed '%s'\n", file)
+		}
+	}
+	return item, true
+}
+
+// parseRmArgs parses rm style arguments, including combined short flags
+// (-rf) and -- to end option parsing.
+func parseRmArgs(args []string) (rmOptions, []string, error) {
+	opts := rmOptions{PreserveRoot: true}
+	var files []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			files = append(files, args[i+1:]...)
+			return opts, files, nil
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg, "=")
+			switch name {
+			case "--recursive":
+				opts.Recursive = true
+			case "--force":
+				opts.Force, opts.Interactive = true, "never"
+			case "--dir":
+				opts.Dir = true
+			case "--verbose":
+				opts.Verbose = true
+			case "--one-file-system":
+				opts.OneFileSystem = true
+			case "--preserve-root":
+				opts.PreserveRoot = true
+			case "--no-preserve-root":
+				opts.PreserveRoot = false
+			case "--allow-protected":
+				opts.AllowProtected = true
+			case "--allow-uncommitted":
+				opts.AllowUncommitted = true
+			case "--no-hooks":
+				opts.NoHooks = true
+			case "--interactive":
+				if !hasValue {
+					value = "always"
+				}
+				switch value {
+				case "never", "no", "none":
+					opts.Interactive = "never"
+				case "once":
+					opts.Force, opts.Interactive = false, "once"
+				case "always", "yes":
+					opts.Force, opts.Interactive = false, "always"
+				default:
+					return opts, nil, fmt.Errorf("invalid argument '%s' for '--interactive'", value)
+				}
+			case "--help":
+				showRmUsage()
+				os.Exit(0)
+			case "--version":
+				ShowVersion()
+				os.Exit(0)
+			default:
+				return opts, nil, fmt.Errorf("unrecognized option '%s'", arg)
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			for _, c := range arg[1:] {
+				switch c {
+				case 'r', 'R':
+					opts.Recursive = true
+				case 'f':
+					opts.Force, opts.Interactive = true, "never"
+				case 'i':
+					opts.Force, opts.Interactive = false, "always"
+				case 'I':
+					opts.Force, opts.Interactive = false, "once"
+				case 'd':
+					opts.Dir = true
+				case 'v':
+					opts.Verbose = true
+				default:
+					return opts, nil, fmt.Errorf("invalid option -- '%c'", c)
+				}
+			}
+		default:
+			files = append(files, arg)
+		}
+	}
+
+	return opts, files, nil
+}
+
+// rmPrompt asks a question on stderr and reads the answer from stdin, like
+// rm does even when stdin is not a terminal.
+func rmPrompt(stdin *bufio.Reader, question string) bool {
+	fmt.Fprint(os.Stderr, question)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	return strings.HasPrefix(answer, "y") || strings.HasPrefix(answer, "Y")
+}
+
+func printGitChanges(changes []types.GitChange) {
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "  %s (%s)\n", change.Path, change.State)
+	}
+}
+
+func rmFileKind(stat os.FileInfo) string {
+	switch {
+	case stat.Mode()&os.ModeSymlink != 0:
+		return "symbolic link"
+	case stat.IsDir():
+		return "directory"
+	case stat.Size() == 0:
+		return "regular empty file"
+	default:
+		return "regular file"
+	}
+}
+
+// rmErrText turns an error into rm's capitalized errno message, e.g.
+// "No such file or directory".
+func rmErrText(err error) string {
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		text := errno.Error()
+		return strings.ToUpper(text[:1]) + text[1:]
+	}
+	return err.Error()
+}
+
+func showRmUsage() {
+	fmt.Println("Usage: vx rm [OPTION]... [FILE]...")
+	fmt.Println("Move the FILE(s) to the vanish cache, accepting rm's options.")
+	fmt.Println()
+	for _, f := range rmFlagDefs {
+		fmt.Printf("  %-30s %s\n", strings.Join(f.Names, ", ")+f.Value, f.Help)
+	}
+	fmt.Println()
+	fmt.Println(rmNotes)
+}
@@ -0,0 +1,307 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+
+	"vanish/internal/helpers"
+	"vanish/internal/tui"
+	"vanish/internal/types"
+)
+
+type historyModel struct {
+	entries     []types.LogEntry // newest first
+	cached      map[int]types.DeletedItem
+	filter      helpers.HistoryFilter
+	config      types.Config
+	cursor      int
+	currentPage int
+	totalPages  int
+	styles      types.ThemeStyles
+	restore     *types.DeletedItem // chosen with r, restored after the view quits
+	err         error
+}
+
+type loadHistoryMsg struct {
+	entries []types.LogEntry
+	cached  map[int]types.DeletedItem
+	err     error
+}
+
+// loadHistoryCmd reads the log, newest entry first, and notes which DELETE
+// entries are still in the cache.
+func loadHistoryCmd(filter helpers.HistoryFilter, config types.Config) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := helpers.ReadHistory(filter, config)
+		if err != nil {
+			return loadHistoryMsg{err: err}
+		}
+		index, err := helpers.LoadIndex(config)
+		if err != nil {
+			return loadHistoryMsg{err: err}
+		}
+
+		items := helpers.NewLoggedItems(index)
+		newest := make([]types.LogEntry, len(entries))
+		cached := make(map[int]types.DeletedItem)
+		for i, entry := range entries {
+			j := len(entries) - 1 - i
+			newest[j] = entry
+			if entry.Operation == "DELETE" && entry.Outcome == helpers.OutcomeOK {
+				if item, ok := items.Find(entry); ok {
+					cached[j] = item
+				}
+			}
+		}
+		return loadHistoryMsg{entries: newest, cached: cached}
+	}
+}
+
+func (m historyModel) Init() tea.Cmd {
+	return loadHistoryCmd(m.filter, m.config)
+}
+
+func (m historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+
+		case "r", "enter":
+			if item, ok := m.cached[m.cursor]; ok {
+				m.restore = &item
+				return m, tea.Quit
+			}
+
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+				if m.cursor < m.currentPage*itemsPerPage {
+					m.currentPage--
+				}
+			}
+
+		case "down", "j":
+			if m.cursor < len(m.entries)-1 {
+				m.cursor++
+				if m.cursor >= (m.currentPage+1)*itemsPerPage {
+					m.currentPage++
+				}
+			}
+
+		case "left", "h", "pgup":
+			if m.currentPage > 0 {
+				m.currentPage--
+				m.cursor = m.currentPage * itemsPerPage
+			}
+
+		case "right", "l", "pgdown":
+			if m.currentPage < m.totalPages-1 {
+				m.currentPage++
+				m.cursor = m.currentPage * itemsPerPage
+			}
+
+		case "home", "g":
+			m.cursor = 0
+			m.currentPage = 0
+
+		case "end", "G":
+			m.cursor = max(len(m.entries)-1, 0)
+			m.currentPage = max(m.totalPages-1, 0)
+		}
+
+	case loadHistoryMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, tea.Quit
+		}
+		m.entries = msg.entries
+		m.cached = msg.cached
+		m.totalPages = (len(m.entries) + itemsPerPage - 1) / itemsPerPage
+	}
+
+	return m, nil
+}
+
+func (m historyModel) View() string {
+	if m.err != nil {
+		return fmt.Sprintf("Error reading history: %v\n", m.err)
+	}
+	if m.restore != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.Title.Render(fmt.Sprintf("History (%d entries)", len(m.entries))))
+	b.WriteString("\n")
+
+	if len(m.entries) == 0 {
+		b.WriteString(m.styles.Info.Render("No logged operations found."))
+		b.WriteString("\n\n")
+		b.WriteString(m.styles.Help.Render("Press q to quit"))
+		return b.String()
+	}
+
+	b.WriteString(m.styles.Help.Render(fmt.Sprintf("Page %d of %d", m.currentPage+1, m.totalPages)))
+	b.WriteString("\n")
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.config.UI.Colors.Primary)).
+		Background(lipgloss.Color(m.config.UI.Colors.Border)).
+		Padding(0, 1)
+	header := fmt.Sprintf("%-19s | %-9s | %-6s | %-8s | %s", "Time", "Operation", "Result", "Cached", "Path")
+	b.WriteString(headerStyle.Render(header))
+	b.WriteString("\n")
+
+	visibleStart := m.currentPage * itemsPerPage
+	visibleEnd := minInt(len(m.entries), visibleStart+itemsPerPage)
+	for i := visibleStart; i < visibleEnd; i++ {
+		b.WriteString(m.formatEntry(i))
+		b.WriteString("\n")
+	}
+
+	// Details of the selected entry
+	entry := m.entries[m.cursor]
+	b.WriteString("\n")
+	if entry.CachePath != "" {
+		b.WriteString(m.styles.Info.Render("Cache path: " + entry.CachePath))
+		b.WriteString("\n")
+	}
+	if entry.User != "" {
+		b.WriteString(m.styles.Info.Render(fmt.Sprintf("By %s (pid %d)", entry.User, entry.PID)))
+		b.WriteString("\n")
+	}
+	if entry.Error != "" {
+		b.WriteString(m.styles.Error.Render("Error: " + entry.Error))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	help := "↑/k up • ↓/j down • ←/h prev page • →/l next page • g home • G end • q quit"
+	if _, ok := m.cached[m.cursor]; ok {
+		help = "r restore • " + help
+	}
+	b.WriteString(m.styles.Help.Render(help))
+
+	return b.String()
+}
+
+func (m historyModel) formatEntry(i int) string {
+	entry := m.entries[i]
+
+	result := entry.Outcome
+	resultColor := lipgloss.Color(m.config.UI.Colors.Success)
+	switch {
+	case entry.Outcome == helpers.OutcomeError:
+		resultColor = lipgloss.Color(m.config.UI.Colors.Error)
+	case entry.Level == helpers.LevelWarn:
+		result = "warn"
+		resultColor = lipgloss.Color(m.config.UI.Colors.Warning)
+	}
+
+	cached := ""
+	if _, ok := m.cached[i]; ok {
+		cached = "yes"
+	}
+
+	line := fmt.Sprintf("%-19s | %-9s | %-6s | %-8s | %s",
+		entry.Time.Local().Format("2006-01-02 15:04:05"),
+		entry.Operation,
+		lipgloss.NewStyle().Foreground(resultColor).Render(fmt.Sprintf("%-6s", result)),
+		cached,
+		historySubject(entry),
+	)
+
+	if i == m.cursor {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.config.UI.Colors.Text)).
+			Background(lipgloss.Color(m.config.UI.Colors.Highlight)).
+			Bold(true).
+			Render(line)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(m.config.UI.Colors.Text)).Render(line)
+}
+
+// historySubject is what an entry is about: the original path of an item,
+// or the message of other entries.
+func historySubject(entry types.LogEntry) string {
+	if entry.OriginalPath != "" {
+		return entry.OriginalPath
+	}
+	return entry.Message
+}
+
+// ShowHistory shows the logged operations passing filter. format "plain"
+// prints one line per entry and "json" a JSON array, oldest first; without
+// a format a terminal gets an interactive view, newest first, from which a
+// DELETE entry still in the cache can be restored.
+func ShowHistory(filter helpers.HistoryFilter, format string, opts types.Options, config types.Config) error {
+	if format == "" && !term.IsTerminal(int(os.Stdout.Fd())) {
+		format = "plain"
+	}
+
+	switch format {
+	case "json":
+		entries, err := helpers.ReadHistory(filter, config)
+		if err != nil {
+			return err
+		}
+		if entries == nil {
+			entries = []types.LogEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode history: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+
+	case "plain":
+		entries, err := helpers.ReadHistory(filter, config)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			line := fmt.Sprintf("%s  %-9s %-5s  %s", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Operation, entry.Outcome, historySubject(entry))
+			if entry.ItemID != "" {
+				line += "  [" + entry.ItemID + "]"
+			}
+			if entry.Error != "" {
+				line += "  error: " + entry.Error
+			}
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	model := historyModel{filter: filter, config: config, styles: helpers.CreateThemeStyles(config)}
+	final, err := tea.NewProgram(model).Run()
+	if err != nil {
+		return fmt.Errorf("error running TUI: %v", err)
+	}
+
+	chosen := final.(historyModel).restore
+	if chosen == nil {
+		return nil
+	}
+	// Restore through the regular TUI, with its confirmation and hooks
+	m, err := tui.InitialModel([]string{chosen.ID}, "restore", opts)
+	if err != nil {
+		return err
+	}
+	if _, err := tea.NewProgram(m).Run(); err != nil {
+		return fmt.Errorf("error running TUI: %v", err)
+	}
+	return nil
+}
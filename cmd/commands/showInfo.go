@@ -16,7 +16,8 @@ import (
 
 type infoModel struct {
 	config        types.Config
-	pattern       string
+	patterns      []string
+	tags          []string
 	index         types.Index
 	styles        types.ThemeStyles
 	matchingItems []types.DeletedItem
@@ -75,12 +76,19 @@ func (m *infoModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *infoModel) findMatches() {
-	m.matchingItems = []types.DeletedItem{}
-	for _, item := range m.index.Items {
-		if strings.Contains(strings.ToLower(item.OriginalPath), strings.ToLower(m.pattern)) {
-			m.matchingItems = append(m.matchingItems, item)
-		}
+	m.matchingItems = helpers.FilterItems(m.index.Items, m.patterns, m.tags)
+}
+
+// query describes the search for titles, e.g. "foo", "bar" #cleanup
+func (m *infoModel) query() string {
+	var parts []string
+	for _, pattern := range m.patterns {
+		parts = append(parts, fmt.Sprintf("%q", pattern))
+	}
+	for _, tag := range m.tags {
+		parts = append(parts, "#"+tag)
 	}
+	return strings.Join(parts, " ")
 }
 
 func (m *infoModel) View() string {
@@ -91,7 +99,7 @@ func (m *infoModel) View() string {
 	var sections []string
 
 	// Title
-	title := m.styles.Title.Render(fmt.Sprintf("🔍 Search Results for \"%s\"", m.pattern))
+	title := m.styles.Title.Render(fmt.Sprintf("🔍 Search Results for %s", m.query()))
 	sections = append(sections, title)
 
 	// Summary
@@ -117,7 +125,7 @@ func (m *infoModel) View() string {
 
 func (m *infoModel) renderNotFound() string {
 	icon := m.styles.IconStyle.Foreground(lipgloss.Color(m.config.UI.Colors.Warning)).Render("🔍")
-	notFoundMsg := m.styles.Warning.Render(fmt.Sprintf("No matches found for \"%s\"", m.pattern))
+	notFoundMsg := m.styles.Warning.Render(fmt.Sprintf("No matches found for %s", m.query()))
 
 	hint := m.styles.Help.Render("💡 Try using vx --list to see all cached items")
 
@@ -218,6 +226,34 @@ func (m *infoModel) renderSingleItem(item types.DeletedItem) string {
 		rows = append(rows, fmt.Sprintf("  %s %s", filesLabel, filesValue))
 	}
 
+	// Tags and note
+	if len(item.Tags) > 0 {
+		tagsLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Tags:")
+		tagsValue := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Primary)).Render("#" + strings.Join(item.Tags, " #"))
+		rows = append(rows, fmt.Sprintf("  %s %s", tagsLabel, tagsValue))
+	}
+	if item.Note != "" {
+		noteLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Note:")
+		noteValue := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Text)).Italic(true).Render(item.Note)
+		rows = append(rows, fmt.Sprintf("  %s %s", noteLabel, noteValue))
+	}
+
+	if item.BatchID != "" {
+		batchLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Batch:")
+		batchValue := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Secondary)).Render(item.BatchID)
+		rows = append(rows, fmt.Sprintf("  %s %s", batchLabel, batchValue))
+	}
+
+	if item.GitRepo != "" {
+		gitText := item.GitRepo
+		if item.GitHead != "" {
+			gitText += " @ " + shortHash(item.GitHead)
+		}
+		gitLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Git:")
+		gitValue := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Secondary)).Render(gitText)
+		rows = append(rows, fmt.Sprintf("  %s %s", gitLabel, gitValue))
+	}
+
 	rows = append(rows, "")
 
 	// Timing information
@@ -230,7 +266,13 @@ func (m *infoModel) renderSingleItem(item types.DeletedItem) string {
 	expiryDate := item.DeleteDate.Add(time.Duration(m.config.Cache.Days) * 24 * time.Hour)
 	daysLeft := int(time.Until(expiryDate).Hours() / 24)
 
-	if daysLeft > 0 {
+	if item.Pinned {
+		pinIcon := "📌"
+		statusLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Status:")
+		statusText := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Primary)).Bold(true).Render("PINNED")
+		pinHint := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Italic(true).Render("(never expires, unpin with vx --unpin " + item.ID + ")")
+		rows = append(rows, fmt.Sprintf("  %s %s %s %s", pinIcon, statusLabel, statusText, pinHint))
+	} else if daysLeft > 0 {
 		expiryIcon := "⏰"
 		expiryLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Expires:")
 		expiryValue := m.styles.StatusGood.Render(expiryDate.Format("2006-01-02 15:04:05"))
@@ -249,7 +291,7 @@ func (m *infoModel) renderSingleItem(item types.DeletedItem) string {
 	// Restore command
 	restoreIcon := m.styles.IconStyle.Render("🔄")
 	restoreLabel := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Muted)).Render("Restore:")
-	restoreCmd := m.styles.Filename.Render(fmt.Sprintf("vx --restore %s", m.pattern))
+	restoreCmd := m.styles.Filename.Render(fmt.Sprintf("vx --restore %s", item.ID))
 	rows = append(rows, fmt.Sprintf("  %s %s %s", restoreIcon, restoreLabel, restoreCmd))
 
 	content := lipgloss.JoinVertical(lipgloss.Left, rows...)
@@ -278,14 +320,16 @@ func (m *infoModel) renderHelp() string {
 	return lipgloss.NewStyle().MarginTop(1).Render(helpText)
 }
 
-// ShowInfo searches for cached items matching the given pattern and displays
-// detailed metadata for each item using a beautiful Bubble Tea TUI.
-func ShowInfo(pattern string, config types.Config) error {
+// ShowInfo searches for cached items matching any of the given patterns and
+// carrying all of the given tags, and displays detailed metadata for each
+// item using a beautiful Bubble Tea TUI.
+func ShowInfo(patterns, tags []string, config types.Config) error {
 	styles := helpers.CreateThemeStyles(config)
 
 	m := &infoModel{
 		config:       config,
-		pattern:      pattern,
+		patterns:     patterns,
+		tags:         tags,
 		styles:       styles,
 		itemsPerPage: 3, // Show 3 items per page
 	}
@@ -299,3 +343,11 @@ func ShowInfo(pattern string, config types.Config) error {
 
 	return nil
 }
+
+// shortHash abbreviates a commit hash the way git log --oneline does.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
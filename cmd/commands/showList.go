@@ -21,6 +21,7 @@ const (
 
 type listModel struct {
 	items       []types.DeletedItem
+	tags        []string // only show items carrying all of these tags
 	config      types.Config
 	cursor      int
 	currentPage int
@@ -34,12 +35,13 @@ type loadIndexMsg struct {
 	err   error
 }
 
-func loadIndexCmd(config types.Config) tea.Cmd {
+func loadIndexCmd(config types.Config, tags []string) tea.Cmd {
 	return func() tea.Msg {
 		index, err := helpers.LoadIndex(config)
 		if err != nil {
 			return loadIndexMsg{err: err}
 		}
+		index.Items = helpers.FilterItems(index.Items, nil, tags)
 
 		// Sort by delete date (newest first)
 		sort.Slice(index.Items, func(i, j int) bool {
@@ -50,16 +52,17 @@ func loadIndexCmd(config types.Config) tea.Cmd {
 	}
 }
 
-func initialModel(config types.Config) listModel {
+func initialModel(config types.Config, tags []string) listModel {
 	return listModel{
 		config:      config,
+		tags:        tags,
 		currentPage: 0,
 		styles:      helpers.CreateThemeStyles(config),
 	}
 }
 
 func (m listModel) Init() tea.Cmd {
-	return loadIndexCmd(m.config)
+	return loadIndexCmd(m.config, m.tags)
 }
 
 func (m listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -149,6 +152,9 @@ func (m listModel) View() string {
 
 	// Title
 	title := fmt.Sprintf("Cached Files (%d items)", len(m.items))
+	if len(m.tags) > 0 {
+		title += " tagged #" + strings.Join(m.tags, " #")
+	}
 	b.WriteString(m.styles.Title.Render(title))
 	b.WriteString("\n")
 
@@ -188,6 +194,12 @@ func (m listModel) View() string {
 		b.WriteString("\n")
 	}
 
+	// Note of the selected item, if any
+	if m.cursor < len(m.items) && m.items[m.cursor].Note != "" {
+		b.WriteString(m.styles.Info.Render("Note: " + m.items[m.cursor].Note))
+		b.WriteString("\n")
+	}
+
 	// Navigation info
 	b.WriteString("\n")
 	if m.totalPages > 1 {
@@ -213,8 +225,13 @@ func (m listModel) formatItem(item types.DeletedItem, isSelected bool) string {
 	daysLeft := int(time.Until(expiryDate).Hours() / 24)
 
 	status := "OK"
+	daysLeftText := fmt.Sprintf("%d days", daysLeft)
 	var statusColor lipgloss.Color
-	if daysLeft <= 0 {
+	if item.Pinned {
+		status = "PINNED"
+		daysLeftText = "never"
+		statusColor = lipgloss.Color(m.config.UI.Colors.Primary)
+	} else if daysLeft <= 0 {
 		status = "EXPIRED"
 		statusColor = lipgloss.Color(m.config.UI.Colors.Error)
 	} else if daysLeft <= 2 {
@@ -234,9 +251,12 @@ func (m listModel) formatItem(item types.DeletedItem, isSelected bool) string {
 		item.DeleteDate.Format("2006-01-02 15:04"),
 		helpers.FormatBytes(item.Size),
 		statusStyle.Render(status),
-		fmt.Sprintf("%d days", daysLeft),
+		daysLeftText,
 		item.OriginalPath,
 	)
+	if len(item.Tags) > 0 {
+		line += "  #" + strings.Join(item.Tags, " #")
+	}
 
 	// Apply selection style if this is the cursor position
 	if isSelected {
@@ -260,9 +280,10 @@ func minInt(a, b int) int {
 	return b
 }
 
-// ShowList displays an interactive TUI list of cached files and directories
-func ShowList(config types.Config) error {
-	p := tea.NewProgram(initialModel(config))
+// ShowList displays an interactive TUI list of cached files and directories,
+// optionally limited to items carrying all of the given tags.
+func ShowList(config types.Config, tags []string) error {
+	p := tea.NewProgram(initialModel(config, tags))
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("error running TUI: %v", err)
 	}
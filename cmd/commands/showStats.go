@@ -22,6 +22,7 @@ type statsModel struct {
 	fileCount     int
 	dirCount      int
 	expiredCount  int
+	pinnedCount   int
 	retentionDays int
 	cacheDir      string
 	width         int
@@ -94,7 +95,11 @@ func (m *statsModel) calculateStats() {
 			m.fileCount++
 		}
 
-		if item.DeleteDate.Before(cutoff) {
+		if item.Pinned {
+			m.pinnedCount++
+		}
+
+		if item.IsExpired(cutoff) {
 			m.expiredCount++
 		}
 
@@ -293,11 +298,16 @@ func (m *statsModel) buildRetentionInfo() string {
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 	styledBar := barStyle.Render(bar)
 
-	return lipgloss.JoinVertical(lipgloss.Left,
-		"",
-		statusMsg,
-		m.styles.Progress.Render(styledBar),
-	)
+	lines := []string{"", statusMsg, m.styles.Progress.Render(styledBar)}
+
+	// Pinned items are excluded from the expired count, call them out separately
+	if m.pinnedCount > 0 {
+		pinnedMsg := m.styles.Info.Foreground(lipgloss.Color(m.config.UI.Colors.Primary)).
+			Render(fmt.Sprintf("📌 %d pinned item(s) kept until unpinned", m.pinnedCount))
+		lines = append(lines, pinnedMsg)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
 func (m *statsModel) buildFooter() string {
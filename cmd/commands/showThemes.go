@@ -62,6 +62,20 @@ func ShowThemesWithTuiPreview(displayer ThemeDisplayer) {
 	fmt.Println("Config location:", displayer.GetConfigPath())
 }
 
+// ShowThemePreview prints the preview of a single built-in theme.
+func ShowThemePreview(name string) error {
+	if _, exists := config.GetDefaultThemes()[name]; !exists {
+		return fmt.Errorf("unknown theme '%s', run 'vx themes' to see all themes", name)
+	}
+
+	displayer := &MainThemeDisplayer{}
+	fmt.Printf("%s:\n", strings.ToUpper(name))
+	fmt.Println(strings.Repeat("-", 40))
+	fmt.Print(displayer.RenderThemePreview(name))
+	fmt.Printf("\nTo use it, set 'theme = \"%s\"' in %s\n", name, displayer.GetConfigPath())
+	return nil
+}
+
 // GetCurrentTheme returns the name of the currently configured theme,
 // falling back to "default" if none is found.
 func (m *MainThemeDisplayer) GetCurrentTheme() string {
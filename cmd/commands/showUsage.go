@@ -11,201 +11,213 @@ import (
 	"vanish/internal/types"
 )
 
-// ShowUsageSmart that detects color support of terminal
-func ShowUsageSmart(config types.Config) {
-	// Check if terminal supports colors
-	if helpers.IsColorTerminal() {
-		ShowUsage(config)
-	} else {
-		ShowUsageFallback(config)
+// usageExamples are shown at the end of the main help.
+var usageExamples = [][2]string{
+	{"vx file1.txt dir1/ *.log", "# Delete multiple items"},
+	{"vx -f *.tmp", "# Delete without confirmation"},
+	{"vx --tag cleanup --note \"old builds\" build/", "# Delete with tag and note"},
+	{"vx rm -rf build/", "# rm-compatible delete"},
+	{`vx restore "*project*"`, "# Restore matching items (same as vx -r)"},
+	{"vx restore --tag cleanup", "# Restore everything tagged cleanup"},
+	{"vx --dry-run --headless build/", "# Print the plan as JSON, change nothing"},
+	{"find . -name '*.orig' -print0 | vx --stdin0", "# Delete paths read from stdin"},
+	{"vx --match '*.pyc' --exclude 'venv/**' .", "# Delete matching paths below ."},
+	{"vx --older-than 30d --larger-than 100MB ~/Downloads", "# Delete old, big downloads"},
+	{"vx purge 30", "# Purge items older than 30 days"},
+	{"vx history --op delete --since 7d", "# Deletes of the last week"},
+	{"vx -- list", "# Delete a file named like a command"},
+	{"source <(vx completion bash)", "# Enable shell completion"},
+}
+
+// usagePrinter renders help text either styled with the theme colors or as
+// plain text. Both variants are generated from the command definitions.
+type usagePrinter struct {
+	styled                                bool
+	title, section, command, desc, flag   lipgloss.Style
+	example, configKey, footer, aliasText lipgloss.Style
+}
+
+const usageColWidth = 34
+
+func newUsagePrinter(config types.Config, styled bool) usagePrinter {
+	return usagePrinter{
+		styled: styled,
+		title: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Primary)).
+			Bold(true).
+			Underline(true),
+		section: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Success)).
+			Bold(true),
+		command: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Highlight)).
+			Bold(true),
+		desc: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Text)),
+		flag: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Secondary)),
+		example: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Muted)).
+			Italic(true),
+		configKey: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Warning)),
+		footer: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Muted)).
+			Italic(true),
+		aliasText: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(config.UI.Colors.Muted)),
 	}
 }
 
-// ShowUsage prints how to use vanish with example
-func ShowUsage(config types.Config) {
-	// Styles
-	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Primary)).
-		Bold(true).
-		Underline(true)
-
-	sectionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Success)).
-		Bold(true).
-		MarginTop(1)
-
-	commandStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Highlight)).
-		Bold(true)
-
-	descStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Text))
-
-	flagStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Secondary))
-
-	exampleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Muted)).
-		Italic(true).
-		MarginLeft(2)
-
-	configStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Warning)).
-		MarginLeft(2)
-
-	footerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(config.UI.Colors.Muted)).
-		Italic(true).
-		Align(lipgloss.Center).
-		MarginTop(1)
-
-	// Helpers
-	const flagColWidth = 28
-
-	printFlag := func(flag, desc string) {
-		fmt.Printf("  %-*s %s\n",
-			flagColWidth,
-			flagStyle.Render(flag),
-			descStyle.Render(desc),
-		)
+func (u usagePrinter) render(style lipgloss.Style, s string) string {
+	if !u.styled {
+		return s
 	}
+	return style.Render(s)
+}
 
-	printCmd := func(cmd, desc string) {
-		fmt.Printf("  %-*s %s\n",
-			flagColWidth,
-			commandStyle.Render(cmd),
-			descStyle.Render(desc),
-		)
+func (u usagePrinter) printTitle(s string) {
+	fmt.Println(u.render(u.title, s))
+	if !u.styled {
+		fmt.Println(strings.Repeat("=", len([]rune(s))))
 	}
+}
 
-	// Title
-	fmt.Println(titleStyle.Render("Vanish (vx) — Safe file/directory removal tool"))
+func (u usagePrinter) printSection(s string) {
 	fmt.Println()
+	if u.styled {
+		fmt.Println(u.render(u.section, s))
+	} else {
+		fmt.Println(s + ":")
+	}
+}
 
-	// Usage
-	fmt.Println(sectionStyle.Render("USAGE"))
-	printCmd("vx <file|dir> [...]", "Safely remove files or directories")
-	fmt.Println()
+// printRow prints a left column padded before styling so ANSI codes do not
+// break the alignment.
+func (u usagePrinter) printRow(leftStyle lipgloss.Style, left, right string) {
+	padded := fmt.Sprintf("%-*s", usageColWidth, left)
+	if len(left) >= usageColWidth {
+		padded = left + "\n" + strings.Repeat(" ", usageColWidth+2)
+	}
+	fmt.Printf("  %s %s\n", u.render(leftStyle, padded), u.render(u.desc, right))
+}
 
-	// File operations
-	fmt.Println(sectionStyle.Render("FILE OPERATIONS"))
-	printCmd("vx <files...>", "Remove files or directories")
-	printFlag("-r, --restore <pattern>", "Restore cached items matching pattern(s)")
-	printFlag("-c, --clear", "Clear entire cache immediately")
-	printFlag("-pr, --purge <days>", "Delete files older than N days")
-	fmt.Println()
+func (u usagePrinter) printFlags(flags []flagDef) {
+	for _, f := range flags {
+		left := strings.Join(f.Names, ", ")
+		if f.Value != "" {
+			left += " " + f.Value
+		}
+		u.printRow(u.flag, left, f.Help)
+	}
+}
 
-	// Information
-	fmt.Println(sectionStyle.Render("INFORMATION"))
-	printFlag("-l, --list", "List all cached files")
-	printFlag("-i, --info <pattern>", "Show detailed info for cached item(s)")
-	printFlag("-s, --stats", "Show cache statistics")
-	printFlag("-p, --path", "Print cache directory path")
-	printFlag("-cp, --config-path", "Print config file path")
-	fmt.Println()
+func (u usagePrinter) printCommands() {
+	for i := range cliCommands {
+		cmd := &cliCommands[i]
+		if cmd.Implicit || cmd.Hidden {
+			continue
+		}
+		left := cmd.Name
+		if cmd.Args != "" {
+			left += " " + cmd.Args
+		}
+		desc := u.render(u.desc, cmd.Summary)
+		if len(cmd.Aliases) > 0 {
+			desc += u.render(u.aliasText, " ("+strings.Join(cmd.Aliases, ", ")+")")
+		}
+		fmt.Printf("  %s %s\n", u.render(u.command, fmt.Sprintf("%-*s", usageColWidth, left)), desc)
+	}
+}
 
-	// Customization
-	fmt.Println(sectionStyle.Render("CUSTOMIZATION"))
-	printFlag("-t, --themes", "Interactive theme selector")
-	fmt.Println()
+func (u usagePrinter) printConfig(config types.Config) {
+	row := func(key, value string) {
+		fmt.Printf("  %s %s\n", u.render(u.configKey, key), u.render(u.desc, value))
+	}
+	row("Cache location:", config.Cache.Directory)
+	row("Retention period:", fmt.Sprintf("%d days", config.Cache.Days))
+	row("Skip confirmations:", fmt.Sprintf("%v", config.Cache.NoConfirm))
+	row("Current theme:", config.UI.Theme)
+	if config.Logging.Enabled {
+		row("Logging:", "enabled → "+config.Logging.Directory)
+	} else {
+		row("Logging:", "disabled")
+	}
+}
 
-	// Options
-	fmt.Println(sectionStyle.Render("OPTIONS"))
-	printFlag("-f, --noconfirm", "Skip confirmation prompts")
-	printFlag("-h, --help", "Show this help message")
-	printFlag("-v, --version", "Show version information")
-	printFlag("-q, --quiet", "Run without UI (delete / clear only)")
-	fmt.Println()
+func (u usagePrinter) printMain(config types.Config) {
+	deleteCmd := &cliCommands[0]
 
-	// Examples
-	fmt.Println(sectionStyle.Render("EXAMPLES"))
-	fmt.Println(exampleStyle.Render("Basic usage:"))
-	printCmd("vx file1.txt", "# Delete a file safely")
-	printCmd("vx file1.txt dir1/ *.log", "# Delete multiple items")
-	printCmd("vx -f *.tmp", "# Delete without confirmation")
-	fmt.Println()
+	u.printTitle("Vanish (vx) — Safe file/directory removal tool")
 
-	fmt.Println(exampleStyle.Render("Recovery:"))
-	printCmd("vx -r file1.txt", "# Restore specific file")
-	printCmd(`vx -r "*project*"`, "# Restore matching files")
-	printCmd(`vx -r "*.pdf" "backup-*"`, "# Restore multiple patterns")
-	fmt.Println()
+	u.printSection("USAGE")
+	u.printRow(u.command, "vx [flags] [--] <files...>", "Move files or directories to the cache")
+	u.printRow(u.command, "vx <command> [flags] [args...]", "Run a command")
 
-	fmt.Println(exampleStyle.Render("Maintenance:"))
-	printCmd("vx -pr 30", "# Purge files older than 30 days")
-	printCmd("vx -s", "# Show cache statistics")
-	printCmd("vx -c", "# Clear entire cache")
-	fmt.Println()
+	u.printSection("COMMANDS")
+	u.printCommands()
 
-	// Current config
-	fmt.Println(sectionStyle.Render("CURRENT CONFIGURATION"))
-	fmt.Printf("  %s %s\n", configStyle.Render("Cache location:"), descStyle.Render(config.Cache.Directory))
-	fmt.Printf("  %s %s\n", configStyle.Render("Retention period:"), descStyle.Render(fmt.Sprintf("%d days", config.Cache.Days)))
-	fmt.Printf("  %s %s\n", configStyle.Render("Skip confirmations:"), descStyle.Render(fmt.Sprintf("%v", config.Cache.NoConfirm)))
-	fmt.Printf("  %s %s\n", configStyle.Render("Current theme:"), descStyle.Render(config.UI.Theme))
+	u.printSection("DELETE FLAGS")
+	u.printFlags(deleteCmd.Flags)
 
-	if config.Logging.Enabled {
-		fmt.Printf("  %s %s\n",
-			configStyle.Render("Logging:"),
-			descStyle.Render("enabled → "+config.Logging.Directory),
-		)
-	} else {
-		fmt.Printf("  %s %s\n",
-			configStyle.Render("Logging:"),
-			descStyle.Render("disabled"),
-		)
+	u.printSection("GLOBAL FLAGS")
+	u.printFlags(globalFlags)
+
+	u.printSection("EXAMPLES")
+	for _, ex := range usageExamples {
+		u.printRow(u.example, ex[0], ex[1])
 	}
 
-	fmt.Println(footerStyle.Render("For more information: https://github.com/Nurysoo/vanish"))
-}
+	u.printSection("CURRENT CONFIGURATION")
+	u.printConfig(config)
 
-// ShowUsageFallback is a alternative fallback to simple output if colors are not available
-func ShowUsageFallback(config types.Config) {
-	fmt.Println("Vanish (vx) - Safe file/directory removal tool")
-	fmt.Println("=" + strings.Repeat("=", 47))
 	fmt.Println()
+	fmt.Println(u.render(u.footer, "Run 'vx <command> --help' for command flags. More: https://github.com/Nurysoo/vanish"))
+}
 
-	fmt.Println("USAGE:")
-	fmt.Println("  vx <file|directory> [file2] [dir2] ...        Remove files/directories safely")
-	fmt.Println()
+func (u usagePrinter) printCommand(cmd *commandDef) {
+	u.printTitle(fmt.Sprintf("vx %s — %s", cmd.Name, cmd.Summary))
 
-	fmt.Println("FILE OPERATIONS:")
-	fmt.Println("  vx <files...>                                 Remove files/directories safely")
-	fmt.Println("  -r, --restore <pattern>...                   Restore files matching patterns")
-	fmt.Println("  -c, --clear                                   Clear all cached files immediately")
-	fmt.Println("  -pr, --purge <days>                           Delete files older than N days")
-	fmt.Println()
+	u.printSection("USAGE")
+	fmt.Println("  " + u.render(u.command, commandSynopsis(cmd)))
+	if len(cmd.Aliases) > 0 {
+		fmt.Println("  " + u.render(u.aliasText, "Also available as "+strings.Join(cmd.Aliases, ", ")))
+	}
+	if len(cmd.Choices) > 0 {
+		fmt.Println("  " + u.render(u.aliasText, "One of: "+strings.Join(cmd.Choices, ", ")))
+	}
 
-	fmt.Println("INFORMATION:")
-	fmt.Println("  -l, --list                                    Show all cached files")
-	fmt.Println("  -i, --info <pattern>                          Show detailed info about cached item(s)")
-	fmt.Println("  -s, --stats                                   Show cache statistics")
-	fmt.Println("  -p, --path                                    Print cache directory path")
-	fmt.Println("  -cp, --config-path                            Print config file path")
-	fmt.Println()
+	if len(cmd.Flags) > 0 {
+		u.printSection("FLAGS")
+		u.printFlags(cmd.Flags)
+	}
 
-	fmt.Println("CUSTOMIZATION:")
-	fmt.Println("  -t, --themes                                  Interactive theme selector")
-	// fmt.Println("  --completion <shell>                          Generate shell completion")
-	fmt.Println()
+	u.printSection("GLOBAL FLAGS")
+	u.printFlags(globalFlags)
+}
 
-	fmt.Println("OPTIONS:")
-	fmt.Println("  -f, --noconfirm                               Skip confirmation prompts")
-	fmt.Println("  -h, --help                                    Show this help message")
-	fmt.Println("  -v, --version                                 Show version information")
-	fmt.Println()
+// ShowUsageSmart that detects color support of terminal
+func ShowUsageSmart(config types.Config) {
+	// Check if terminal supports colors
+	if helpers.IsColorTerminal() {
+		ShowUsage(config)
+	} else {
+		ShowUsageFallback(config)
+	}
+}
 
-	fmt.Println("EXAMPLES:")
-	fmt.Println("  vx file1.txt                                  # Delete file safely")
-	fmt.Println("  vx -r \"*project*\"                             # Restore files matching pattern")
-	fmt.Println("  vx -pr 30                                     # Purge files older than 30 days")
-	fmt.Println()
+// ShowUsage prints how to use vanish with example
+func ShowUsage(config types.Config) {
+	newUsagePrinter(config, true).printMain(config)
+}
+
+// ShowUsageFallback is a alternative fallback to simple output if colors are not available
+func ShowUsageFallback(config types.Config) {
+	newUsagePrinter(config, false).printMain(config)
+}
 
-	fmt.Println("CURRENT CONFIGURATION:")
-	fmt.Printf("  Cache location: %s\n", config.Cache.Directory)
-	fmt.Printf("  Retention period: %d days\n", config.Cache.Days)
-	fmt.Printf("  Current theme: %s\n", config.UI.Theme)
-	fmt.Printf("  Logging: %v\n", config.Logging.Enabled)
-	// fmt.Printf("  Notifications: %v\n", config.Notifications.DesktopEnabled)
-	fmt.Println("\n\nFor more information visit: https://github.com/Nurysoo/vanish")
+// ShowCommandUsage prints the help of a single command, styled when the
+// terminal supports colors.
+func ShowCommandUsage(cmd *commandDef, config types.Config) {
+	newUsagePrinter(config, helpers.IsColorTerminal()).printCommand(cmd)
 }
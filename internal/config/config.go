@@ -10,7 +10,7 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/BurntSushi/toml"
+	"vanish/internal/helpers"
 	"vanish/internal/types"
 )
 
@@ -157,14 +157,9 @@ func GetDefaultThemes() map[string]types.Config {
 	return themes
 }
 
-func createDefaultConfig(configPath string) error {
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return err
-	}
-
-	configContent :=
-		`[cache]
+// defaultConfigContent is the config file written on first run, every
+// setting at its default with comments.
+const defaultConfigContent = `[cache]
 # ============================================================================
 # ⚠️ WARNING: Do not modify the cache directory if it already have files stored!
 #
@@ -174,12 +169,18 @@ func createDefaultConfig(configPath string) error {
 # work as expected, potentially leading to data loss.
 # ============================================================================
 
-# Directory where deleted files are stored (relative to HOME directory)
-directory = ".cache/vanish"
+# Directory where deleted files are stored, relative paths are relative to
+# HOME. Defaults to $XDG_DATA_HOME/vanish, which is ~/.local/share/vanish
+# unless XDG_DATA_HOME is set. It is not kept in ~/.cache, since cache
+# cleaners would wipe the deleted files.
+# directory = "~/.local/share/vanish"
 
 # Number of days to keep deleted files before automatic cleanup
 days = 10
 
+# Skip confirmation prompts (use with caution!)
+no_confirm = false
+
 # ------------------------------
 # Logging Configuration
 # ------------------------------
@@ -187,8 +188,74 @@ days = 10
 # Enable or disable logging (true/false)
 enabled = true
 
-# Directory for log files (relative to the cache directory above)
-directory = ".cache/vanish/logs"
+# Directory for log files, relative paths are relative to HOME. Defaults to
+# $XDG_STATE_HOME/vanish, which is ~/.local/state/vanish unless
+# XDG_STATE_HOME is set
+# directory = "~/.local/state/vanish"
+
+# Log line format: "json" (one object per line) or "text"
+format = "json"
+
+# Least severe records written: "debug", "info", "warn" or "error".
+# debug traces every file operation and index load/save with timings,
+# -v and -vv lower the level to info and debug for a single run
+level = "info"
+
+# Rotate vanish.log once it reaches max_size megabytes (0 never rotates).
+# Rotated logs are named vanish-<time>.log and the oldest are removed
+# beyond max_files (0 keeps all) or after max_age days (0 keeps them)
+max_size = 10
+max_files = 5
+max_age = 0
+
+# Gzip rotated logs (true/false)
+compress = false
+
+# Audit mode: every record carries a sequence number and a sha256 chained
+# to the previous record, deletes and restores the hash of the item's
+# content. Forces the json format. Check the chain with vx --audit-verify
+audit = false
+
+# ------------------------------
+# Safety
+# ------------------------------
+[safety]
+# Paths that vx refuses to delete unless --allow-protected is given.
+# The root and home directories, the cache and log directories and mount
+# points are always protected. Globs without a "/" match the file name at
+# any depth, others match the full path ("~/" and "**" are supported).
+protected = [".git"]
+
+# ------------------------------
+# Hooks
+# ------------------------------
+[hooks]
+# Shell commands run around operations. The affected items are passed as
+# JSON on stdin and in VANISH_HOOK_* environment variables. A failing pre
+# hook cancels the operation, its stderr is shown. Skip them with --no-hooks.
+# pre_delete   = "notify-send vanish \"deleting $VANISH_HOOK_COUNT items\""
+# post_delete  = ""
+# pre_restore  = ""
+# post_restore = ""
+# pre_purge    = ""
+# post_purge   = ""
+# post_clear   = ""
+timeout = 30
+
+# ------------------------------
+# Desktop Notifications
+# ------------------------------
+[notifications]
+# Sent over D-Bus to the desktop's notification service
+desktop_enabled = false
+
+# Notify when a delete or restore running at least min_seconds finishes,
+# and when old items are cleaned up
+notify_success = true
+min_seconds = 10
+
+# Notify when an operation fails
+notify_errors = true
 
 # ------------------------------
 # User Interface (UI) Settings
@@ -197,9 +264,6 @@ directory = ".cache/vanish/logs"
 # Theme options: "default", "dark", "light", "cyberpunk", "minimal", "ocean", "forest", "sunset"
 theme = "default"
 
-# Skip confirmation prompts (use with caution!)
-no_confirm = false
-
 # ------------------------------
 # UI Color Customization
 # Uncomment and customize hex values if you want a custom look.
@@ -224,34 +288,99 @@ no_confirm = false
 # animation   = true         # Smooth animation (disable for performance)
 `
 
-	return os.WriteFile(configPath, []byte(configContent), 0644)
+func createDefaultConfig(configPath string) error {
+	configDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(configPath, []byte(defaultConfigContent), 0644)
 }
 
-// LoadConfig loads the user's configuration from ~/.config/vanish/vanish.toml.
-// If the file does not exist, it creates a default config.
+// DefaultConfig returns the built-in defaults for everything except the UI,
+// which comes from the selected theme.
+func DefaultConfig(homeDir string) types.Config {
+	config := types.Config{}
+	config.Cache.Directory = helpers.XDGDir(homeDir, "XDG_DATA_HOME", filepath.Join(".local", "share"))
+	config.Cache.Days = 10
+	config.Logging.Enabled = true
+	config.Logging.Directory = helpers.XDGDir(homeDir, "XDG_STATE_HOME", filepath.Join(".local", "state"))
+	config.Logging.Format = "json"
+	config.Logging.Level = "info"
+	config.Logging.MaxSize = 10
+	config.Logging.MaxFiles = 5
+	config.Safety.Protected = []string{".git"}
+	config.Hooks.Timeout = 30
+	config.Notifications.NotifySuccess = true
+	config.Notifications.NotifyErrors = true
+	config.Notifications.MinSeconds = 10
+	return config
+}
+
+// LoadConfig loads the user's configuration from ~/.config/vanish/vanish.toml,
+// or $VANISH_CONFIG, with the environment and --set overrides applied (see
+// Overrides). If the file does not exist, it creates a default config.
 // It also applies any matching theme and preserves custom overrides.
+// A file with errors (see CheckConfig) returns an *InvalidConfigError.
 func LoadConfig() (types.Config, error) {
+	config, _, err := Load()
+	return config, err
+}
+
+// Load is LoadConfig that also returns the warnings about the file. On
+// errors the defaults are returned, with the default theme.
+func Load() (types.Config, []Problem, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return types.Config{}, err
+		return types.Config{}, nil, err
 	}
 
-	configPath := filepath.Join(homeDir, ".config", "vanish", "vanish.toml")
+	configPath := helpers.GetConfigPath()
 
-	// Default configuration
-	config := types.Config{}
-	config.Cache.Directory = filepath.Join(homeDir, ".cache", "vanish")
-	config.Cache.Days = 10
-	config.Logging.Enabled = true
-	config.Logging.Directory = filepath.Join(homeDir, ".cache", "vanish", "logs")
+	config := DefaultConfig(homeDir)
+	var problems []Problem
 
 	themes := GetDefaultThemes()
 
+	overrides, err := Overrides()
+	if err != nil {
+		config.UI = themes["default"].UI
+		return config, nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) && os.Getenv(helpers.ConfigEnv) != "" {
+		config.UI = themes["default"].UI
+		return config, nil, fmt.Errorf("%s is set to %s, which does not exist", helpers.ConfigEnv, configPath)
+	}
+	if os.IsNotExist(err) {
+		// Create default config file, unless --dry-run asks to write nothing
+		if !dryRun {
+			if err := createDefaultConfig(configPath); err != nil {
+				log.Printf("Warning: Could not create default config: %v", err)
+			}
+		}
+		data, err = []byte(defaultConfigContent), nil
+	}
+	if err == nil && os.Getenv(helpers.ConfigEnv) == "" {
+		data = migrateLegacyDirs(configPath, data, homeDir)
+	}
+
 	// Try to load config file
-	if _, err := os.Stat(configPath); err == nil {
-		// Load the entire config from file first
-		if _, err := toml.DecodeFile(configPath, &config); err != nil {
-			return config, fmt.Errorf("error parsing config file: %v", err)
+	if err == nil {
+		// Load and check the entire config from file first, then the
+		// environment and --set over it
+		config, problems, err = decodeConfig(configPath, data, homeDir)
+		if err == nil && len(overrides) > 0 {
+			config, problems, err = applyOverrides(configPath, data, problems, overrides, homeDir)
+		}
+		if err == nil {
+			err = configErrors(problems)
+		}
+		if err != nil {
+			config = DefaultConfig(homeDir)
+			config.UI = themes["default"].UI
+			return config, nil, err
 		}
 
 		// fmt.Printf("DEBUG: Loaded theme from config: '%s'\n", config.UI.Theme)
@@ -320,22 +449,45 @@ func LoadConfig() (types.Config, error) {
 			// themeName, config.UI.Colors.Primary, config.UI.Colors.Success)
 
 		} else {
-			// Unknown theme, fall back to default
-			fmt.Printf("WARNING: Unknown theme '%s', falling back to default\n", themeName)
+			// Unknown theme, reported as a warning above, fall back to default
 			defaultTheme := themes["default"]
 			config.UI = defaultTheme.UI
 		}
 
 	} else {
-		// No config file exists, use default theme
-		defaultTheme := themes["default"]
-		config.UI = defaultTheme.UI
+		config.UI = themes["default"].UI
+		return config, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
 
-		// Create default config file
-		if err := createDefaultConfig(configPath); err != nil {
-			log.Printf("Warning: Could not create default config: %v", err)
-		}
+	return config, problems, nil
+}
+
+// Peek returns the user's configuration without any side effect: it does
+// not create, migrate or back up the file, prints no warnings and turns
+// logging off. A missing or broken file gives the defaults, and themes are
+// not applied. Shell completion uses it, as it runs on every Tab press.
+func Peek() types.Config {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return types.Config{}
+	}
+
+	configPath := helpers.GetConfigPath()
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		data = []byte(defaultConfigContent)
+	}
+	if os.Getenv(helpers.ConfigEnv) == "" {
+		data = keepLegacyDirs(data, homeDir)
 	}
 
-	return config, nil
+	config, problems, err := decodeConfig(configPath, data, homeDir)
+	if overrides, oerr := Overrides(); err == nil && oerr == nil && len(overrides) > 0 {
+		config, problems, err = applyOverrides(configPath, data, problems, overrides, homeDir)
+	}
+	if err != nil || configErrors(problems) != nil {
+		config = DefaultConfig(homeDir)
+	}
+	config.Logging.Enabled = false
+	return config
 }
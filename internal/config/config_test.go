@@ -0,0 +1,536 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+func decodeTest(t *testing.T, data string) types.Config {
+	t.Helper()
+	config := DefaultConfig(t.TempDir())
+	if _, err := toml.Decode(data, &config); err != nil {
+		t.Fatalf("result does not decode: %v\n%s", err, data)
+	}
+	return config
+}
+
+func TestSetKeyDefaultFile(t *testing.T) {
+	updated := setKey(defaultConfigContent, "cache.days", "7")
+	updated = setKey(updated, "cache.directory", `"~/trash"`)
+	updated = setKey(updated, "ui.colors.primary", `"#112233"`)
+
+	config := decodeTest(t, updated)
+	if config.Cache.Days != 7 || config.Cache.Directory != "~/trash" || config.UI.Colors.Primary != "#112233" {
+		t.Errorf("unexpected settings: days %d, directory %s, primary %s", config.Cache.Days, config.Cache.Directory, config.UI.Colors.Primary)
+	}
+
+	// Only the three lines change, every comment stays
+	oldLines, newLines := strings.Split(defaultConfigContent, "\n"), strings.Split(updated, "\n")
+	if len(oldLines) != len(newLines) {
+		t.Fatalf("expected %d lines, got %d", len(oldLines), len(newLines))
+	}
+	changed := 0
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			changed++
+		}
+	}
+	if changed != 3 {
+		t.Errorf("expected 3 changed lines, got %d", changed)
+	}
+	if !strings.Contains(updated, `directory = "~/trash"`+"\n") || strings.Contains(updated, `# directory = "~/trash"`) {
+		t.Error("expected the commented-out cache directory to be uncommented")
+	}
+	if !strings.Contains(updated, "\nprimary   = \"#112233\"  # Main accent color\n") {
+		t.Error("expected the commented-out color to be uncommented with its comment")
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		key      string
+		literal  string
+		expected string
+	}{
+		{
+			"Same name in another table",
+			"[cache]\ndirectory = \"a\"\n\n[logging]\ndirectory = \"b\"\n",
+			"logging.directory", `"c"`,
+			"[cache]\ndirectory = \"a\"\n\n[logging]\ndirectory = \"c\"\n",
+		},
+		{
+			"Trailing comment kept",
+			"[cache]\ndays = 10 # two weeks\n",
+			"cache.days", "14",
+			"[cache]\ndays = 14 # two weeks\n",
+		},
+		{
+			"Quoted value containing #",
+			"[ui]\ntheme = \"a#b\" # not the comment\n",
+			"ui.theme", `"dark"`,
+			"[ui]\ntheme = \"dark\" # not the comment\n",
+		},
+		{
+			"Escaped quote",
+			"[hooks]\npre_delete = \"echo \\\"#\\\"\" # say #\n",
+			"hooks.pre_delete", `"true"`,
+			"[hooks]\npre_delete = \"true\" # say #\n",
+		},
+		{
+			"Multi-line array replaced",
+			"[safety]\nprotected = [\n  \".git\", # keep ] this\n  \"node_modules\",\n]\n\n[ui]\ntheme = \"dark\"\n",
+			"safety.protected", `[".svn"]`,
+			"[safety]\nprotected = [\".svn\"]\n\n[ui]\ntheme = \"dark\"\n",
+		},
+		{
+			"Added after a multi-line array",
+			"[logging]\nlevel = [\n  \"x\",\n]\n\n[ui]\n",
+			"logging.format", `"text"`,
+			"[logging]\nlevel = [\n  \"x\",\n]\nformat = \"text\"\n\n[ui]\n",
+		},
+		{
+			"Commented line uncommented",
+			"[cache]\n# days = 10\n",
+			"cache.days", "3",
+			"[cache]\ndays = 3\n",
+		},
+		{
+			"New table",
+			"[cache]\ndays = 10",
+			"hooks.timeout", "5",
+			"[cache]\ndays = 10\n\n[hooks]\ntimeout = 5\n",
+		},
+		{
+			"Quoted key",
+			"[cache]\n\"days\" = 10\n",
+			"cache.days", "2",
+			"[cache]\n\"days\" = 2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setKey(tt.data, tt.key, tt.literal); got != tt.expected {
+				t.Errorf("setKey(%s) =\n%s\nwant\n%s", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommentKey(t *testing.T) {
+	data := "[cache]\ndirectory = \"a\"\n\n[logging]\n  directory = \"b\"\n"
+	want := "[cache]\ndirectory = \"a\"\n\n[logging]\n  # directory = \"b\"\n"
+	if got := commentKey(data, "logging.directory"); got != want {
+		t.Errorf("commentKey =\n%s\nwant\n%s", got, want)
+	}
+	if got := commentKey(data, "ui.theme"); got != data {
+		t.Errorf("expected an unset key to leave the file alone, got\n%s", got)
+	}
+
+	// The comment is what setKey uncomments again
+	if got := setKey(commentKey(data, "cache.directory"), "cache.directory", `"c"`); !strings.HasPrefix(got, "[cache]\ndirectory = \"c\"\n") {
+		t.Errorf("expected setKey to reuse the commented line, got\n%s", got)
+	}
+}
+
+func TestValueLength(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{`10 # days`, 2},
+		{`true`, 4},
+		{`"a#b" # c`, 5},
+		{`'C:\dir' x`, 8},
+		{`"say \"hi\"" # c`, 12},
+		{"[\n  \"a\", # ]\n  \"b\",\n] # end", 21},
+		{`[[1, 2], [3]] x`, 13},
+		{`"unterminated`, 13},
+	}
+
+	for _, tt := range tests {
+		if got := valueLength(tt.value); got != tt.expected {
+			t.Errorf("valueLength(%q) = %d, want %d", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestCheckConfigLines(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	data := `[cache]
+days = 0
+
+[logging]
+# comment
+format = "xml"
+formt = "text"
+
+[ui]
+no_confirm = true
+`
+	problems, err := CheckConfigData("vanish.toml", []byte(data))
+	if err != nil {
+		t.Fatalf("CheckConfigData failed: %v", err)
+	}
+
+	want := []struct {
+		line    int
+		key     string
+		warning bool
+		message string
+	}{
+		{2, "cache.days", false, "at least 1"},
+		{6, "logging.format", true, "unknown format"},
+		{7, "logging.formt", true, "did you mean logging.format"},
+		{10, "ui.no_confirm", true, "deprecated"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %+v", len(want), problems)
+	}
+	for i, w := range want {
+		p := problems[i]
+		if p.Line != w.line || p.Key != w.key || p.Warning != w.warning || !strings.Contains(p.Message, w.message) {
+			t.Errorf("problem %d = %+v, want line %d %s %q", i, p, w.line, w.key, w.message)
+		}
+	}
+
+	problems, _ = CheckConfigData("vanish.toml", []byte("[cache]\n\ndays = = 10\n"))
+	if len(problems) != 1 || problems[0].Line != 3 || problems[0].Key != "" {
+		t.Errorf("expected a syntax error on line 3, got %+v", problems)
+	}
+}
+
+func TestDeprecatedKeys(t *testing.T) {
+	home := t.TempDir()
+
+	config, problems, err := decodeConfig("vanish.toml", []byte("[ui]\nno_confirm = true\n"), home)
+	if err != nil || !config.Cache.NoConfirm {
+		t.Errorf("expected ui.no_confirm to set cache.no_confirm, got %v (%v)", config.Cache.NoConfirm, err)
+	}
+	if configErrors(problems) != nil || len(problems) != 1 {
+		t.Errorf("expected a single warning, got %+v", problems)
+	}
+
+	config, _, _ = decodeConfig("vanish.toml", []byte("[cache]\nno_confirm = false\n\n[ui]\nno_confirm = true\n"), home)
+	if config.Cache.NoConfirm {
+		t.Error("expected cache.no_confirm to win over ui.no_confirm")
+	}
+
+	_, problems, _ = decodeConfig("vanish.toml", []byte("[ui]\nno_confirm = \"yes\"\n"), home)
+	if configErrors(problems) == nil {
+		t.Errorf("expected a type error for ui.no_confirm, got %+v", problems)
+	}
+}
+
+func TestDiffSettings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if changes := DiffSettings([]byte("[cache]\ndays = 10\n"), []byte("[cache]\n\"days\"=10 # same\n")); len(changes) != 0 {
+		t.Errorf("expected no changes between equal settings, got %+v", changes)
+	}
+
+	newData := setKey(setKey(defaultConfigContent, "ui.theme", `"dark"`), "cache.days", "30")
+	newData = "# only a comment differs\n" + newData
+	changes := DiffSettings([]byte(defaultConfigContent), []byte(newData))
+	want := []SettingChange{
+		{Key: "cache.days", Old: "10", New: "30"},
+		{Key: "ui.theme", Old: `"default"`, New: `"dark"`},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestConfigBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vanish.toml")
+
+	if backup, err := BackupConfig(path); err != nil || backup != "" {
+		t.Errorf("expected no backup without a config file, got %q (%v)", backup, err)
+	}
+	if _, err := FindBackup(path, ""); err == nil {
+		t.Error("expected an error without backups")
+	}
+
+	// Older backups, the oldest beyond maxConfigBackups are removed
+	for i := 0; i < maxConfigBackups; i++ {
+		name := backupPrefix + "2020-01-01T00-00-0" + string(rune('0'+i)) + ".000"
+		os.WriteFile(filepath.Join(dir, name), []byte("old"), 0644)
+	}
+	os.WriteFile(path, []byte("[cache]\ndays = 3\n"), 0644)
+	backup, err := BackupConfig(path)
+	if err != nil {
+		t.Fatalf("BackupConfig failed: %v", err)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != "[cache]\ndays = 3\n" {
+		t.Errorf("expected the backup to hold the config file, got %q", data)
+	}
+	backups, _ := ConfigBackups(path)
+	if len(backups) != maxConfigBackups || filepath.Base(backups[0]) != backupPrefix+"2020-01-01T00-00-01.000" {
+		t.Errorf("expected the oldest backup removed, got %v", backups)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", backup},
+		{filepath.Base(backup), backup},
+		{"2020-01-01T00-00-05.000", filepath.Join(dir, backupPrefix+"2020-01-01T00-00-05.000")},
+		{backups[2], backups[2]},
+	}
+	for _, tt := range tests {
+		if got, err := FindBackup(path, tt.name); err != nil || got != tt.expected {
+			t.Errorf("FindBackup(%q) = %q (%v), want %q", tt.name, got, err, tt.expected)
+		}
+	}
+	if _, err := FindBackup(path, "2020-01-01T00-00-00.000"); err == nil {
+		t.Error("expected the removed backup not to be found")
+	}
+	if stamp, err := BackupTime(backups[0]); err != nil || stamp.Year() != 2020 || stamp.Second() != 1 {
+		t.Errorf("BackupTime = %v (%v)", stamp, err)
+	}
+
+	// A rollback is the backup's content written back
+	if err := WriteConfig(path, []byte("[cache]\ndays = 9\n")); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+	latest, _ := FindBackup(path, "")
+	data, _ := os.ReadFile(latest)
+	if changes := DiffSettings(mustRead(t, path), data); len(changes) != 1 || changes[0].New != "3" {
+		t.Errorf("expected rolling back to restore days = 3, got %+v", changes)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEnvNames(t *testing.T) {
+	if got := EnvNames("cache.days"); strings.Join(got, " ") != "VANISH_DAYS VANISH_CACHE_DAYS" {
+		t.Errorf("EnvNames(cache.days) = %v", got)
+	}
+	if got := EnvNames("ui.colors.primary"); strings.Join(got, " ") != "VANISH_UI_COLORS_PRIMARY" {
+		t.Errorf("EnvNames(ui.colors.primary) = %v", got)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	home := t.TempDir()
+	t.Cleanup(func() { SetFlagOverrides(nil) })
+	data := []byte("[cache]\ndays = 10\n")
+
+	days := func(name string) int {
+		t.Helper()
+		overrides, err := Overrides()
+		if err != nil {
+			t.Fatalf("%s: Overrides failed: %v", name, err)
+		}
+		config, problems, err := applyOverrides("vanish.toml", data, nil, overrides, home)
+		if err != nil || len(problems) != 0 {
+			t.Fatalf("%s: applyOverrides failed: %+v (%v)", name, problems, err)
+		}
+		return config.Cache.Days
+	}
+
+	t.Setenv("VANISH_DAYS", "3")
+	if got := days("alias"); got != 3 {
+		t.Errorf("VANISH_DAYS=3 gave %d days", got)
+	}
+	t.Setenv("VANISH_CACHE_DAYS", "7")
+	if got := days("full name"); got != 7 {
+		t.Errorf("expected VANISH_CACHE_DAYS to win over VANISH_DAYS, got %d days", got)
+	}
+	SetFlagOverrides([]string{"cache.days=5"})
+	if got := days("--set"); got != 5 {
+		t.Errorf("expected --set to win over the environment, got %d days", got)
+	}
+
+	SetFlagOverrides([]string{"cache.days"})
+	if _, err := Overrides(); err == nil || !strings.Contains(err.Error(), "key=value") {
+		t.Errorf("expected an error for --set without a value, got %v", err)
+	}
+	SetFlagOverrides(nil)
+	t.Setenv("VANISH_CACHE_DAYS", "")
+	t.Setenv("VANISH_DAYS", "ten")
+	if _, err := Overrides(); err == nil || !strings.HasPrefix(err.Error(), "VANISH_DAYS:") {
+		t.Errorf("expected an error naming VANISH_DAYS, got %v", err)
+	}
+
+	// An override's problems are reported under its source, and the file's
+	// problems with the overridden key no longer apply
+	data = []byte("[cache]\ndays = 0\n")
+	fileProblems, _ := CheckConfigData("vanish.toml", data)
+	_, problems, _ := applyOverrides("vanish.toml", data, fileProblems, []Override{{Key: "cache.days", Literal: "5", Source: "VANISH_DAYS"}}, home)
+	if len(problems) != 0 {
+		t.Errorf("expected the override to replace the file's problem, got %+v", problems)
+	}
+	_, problems, _ = applyOverrides("vanish.toml", data, fileProblems, []Override{{Key: "cache.days", Literal: "-1", Source: "--set"}}, home)
+	if len(problems) != 1 || problems[0].File != "--set" || problems[0].Line != 0 {
+		t.Errorf("expected the problem reported under --set, got %+v", problems)
+	}
+}
+
+func TestMigrateLegacyDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	defaults := DefaultConfig(home)
+
+	oldStore := filepath.Join(home, legacyCacheDir)
+	oldLogs := filepath.Join(home, legacyLogDir)
+	os.MkdirAll(oldLogs, 0755)
+	os.WriteFile(filepath.Join(oldLogs, helpers.LogFileName), []byte("{}\n"), 0644)
+	os.WriteFile(filepath.Join(oldStore, "1-notes.txt"), []byte("notes"), 0644)
+	oldConfig := defaults
+	oldConfig.Cache.Directory = oldStore
+	helpers.SaveIndex(types.Index{Items: []types.DeletedItem{
+		{ID: "1", OriginalPath: "/tmp/notes.txt", CachePath: filepath.Join(oldStore, "1-notes.txt")},
+	}}, oldConfig)
+
+	configPath := filepath.Join(home, ".config", "vanish", "vanish.toml")
+	content := "[cache]\ndirectory = \".cache/vanish\"\ndays = 10\n\n[logging]\ndirectory = \".cache/vanish/logs\"\n"
+	os.MkdirAll(filepath.Dir(configPath), 0755)
+	os.WriteFile(configPath, []byte(content), 0644)
+
+	data := migrateLegacyDirs(configPath, []byte(content), home)
+
+	if _, err := os.Stat(oldStore); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be moved away, got %v", oldStore, err)
+	}
+	if _, err := os.Stat(filepath.Join(defaults.Logging.Directory, helpers.LogFileName)); err != nil {
+		t.Errorf("expected the logs in %s: %v", defaults.Logging.Directory, err)
+	}
+	index, err := helpers.LoadIndex(defaults)
+	if err != nil || len(index.Items) != 1 {
+		t.Fatalf("expected the index in %s, got %+v (%v)", defaults.Cache.Directory, index, err)
+	}
+	if want := filepath.Join(defaults.Cache.Directory, "1-notes.txt"); index.Items[0].CachePath != want {
+		t.Errorf("expected cache_path %s, got %s", want, index.Items[0].CachePath)
+	}
+	if _, err := os.Stat(index.Items[0].CachePath); err != nil {
+		t.Errorf("expected the cached file at its new cache_path: %v", err)
+	}
+
+	want := "[cache]\n# directory = \".cache/vanish\"\ndays = 10\n\n[logging]\n# directory = \".cache/vanish/logs\"\n"
+	if string(data) != want {
+		t.Errorf("expected the old directories commented out, got\n%s", data)
+	}
+	if onDisk := mustRead(t, configPath); string(onDisk) != want {
+		t.Errorf("expected the config file rewritten, got\n%s", onDisk)
+	}
+	if backups, _ := ConfigBackups(configPath); len(backups) != 1 || string(mustRead(t, backups[0])) != content {
+		t.Errorf("expected one backup of the old file, got %v", backups)
+	}
+	config := defaults
+	toml.Decode(string(data), &config)
+	if config.Cache.Directory != defaults.Cache.Directory || config.Logging.Directory != defaults.Logging.Directory {
+		t.Errorf("expected the new defaults to apply, got %s and %s", config.Cache.Directory, config.Logging.Directory)
+	}
+
+	// A second run finds nothing to do
+	if again := migrateLegacyDirs(configPath, data, home); string(again) != string(data) {
+		t.Errorf("expected a second run to change nothing, got\n%s", again)
+	}
+	if backups, _ := ConfigBackups(configPath); len(backups) != 1 {
+		t.Errorf("expected no new backup on a second run, got %v", backups)
+	}
+}
+
+func TestMigrateLegacyDirsDryRun(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	SetDryRun(true)
+	t.Cleanup(func() { SetDryRun(false) })
+
+	oldStore := filepath.Join(home, legacyCacheDir)
+	os.MkdirAll(oldStore, 0755)
+	configPath := filepath.Join(home, "vanish.toml")
+
+	data := migrateLegacyDirs(configPath, []byte(defaultConfigContent), home)
+	if config := decodeTest(t, string(data)); config.Cache.Directory != oldStore {
+		t.Errorf("expected a dry run to use %s, got %s", oldStore, config.Cache.Directory)
+	}
+	if _, err := os.Stat(oldStore); err != nil {
+		t.Errorf("expected a dry run to leave %s in place: %v", oldStore, err)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Errorf("expected a dry run to write no config file, got %v", err)
+	}
+}
+
+func TestMigrateLegacyDirsFailed(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+
+	oldStore := filepath.Join(home, legacyCacheDir)
+	oldLogs := filepath.Join(home, legacyLogDir)
+	os.MkdirAll(oldLogs, 0755)
+	// A file where the XDG directories go makes both moves fail
+	os.WriteFile(filepath.Join(home, ".local"), nil, 0644)
+
+	configPath := filepath.Join(home, "vanish.toml")
+	content := "[cache]\ndays = 10\n"
+	os.WriteFile(configPath, []byte(content), 0644)
+
+	data := migrateLegacyDirs(configPath, []byte(content), home)
+	config := decodeTest(t, string(data))
+	if config.Cache.Directory != oldStore || config.Logging.Directory != oldLogs {
+		t.Errorf("expected the old directories after a failed move, got %s and %s", config.Cache.Directory, config.Logging.Directory)
+	}
+	if _, err := os.Stat(oldStore); err != nil {
+		t.Errorf("expected %s to stay in place: %v", oldStore, err)
+	}
+	if onDisk := mustRead(t, configPath); string(onDisk) != content {
+		t.Errorf("expected the config file left alone, got\n%s", onDisk)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(helpers.ConfigEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+
+	// Before the migration, Peek finds the store where it is
+	oldStore := filepath.Join(home, legacyCacheDir)
+	os.MkdirAll(oldStore, 0755)
+	if config := Peek(); config.Cache.Directory != oldStore || config.Logging.Enabled {
+		t.Errorf("Peek() = %s with logging %v, want %s without logging", config.Cache.Directory, config.Logging.Enabled, oldStore)
+	}
+
+	entries, _ := os.ReadDir(home)
+	if len(entries) != 1 {
+		t.Errorf("expected Peek to create nothing, HOME holds %d entries", len(entries))
+	}
+	if _, err := os.Stat(oldStore); err != nil {
+		t.Errorf("expected Peek to leave %s in place: %v", oldStore, err)
+	}
+}
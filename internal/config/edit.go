@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"vanish/internal/types"
+)
+
+// --- Editing ---
+
+// GetValue returns the value of a dotted key, e.g. "cache.days", in config.
+func GetValue(config types.Config, key string) (any, error) {
+	// Walked like Schema, so Default holds the value in config
+	var fields []SchemaField
+	walkSchema(reflect.ValueOf(config), "", &fields)
+	for _, field := range fields {
+		if field.Key == key {
+			return field.Default, nil
+		}
+	}
+	return nil, unknownKeyError(key)
+}
+
+func unknownKeyError(key string) error {
+	fields, tables := schemaIndex()
+	if tables[key] {
+		return fmt.Errorf("%s is a table, give one of its keys, e.g. %s", key, firstKeyOf(key))
+	}
+	if suggestion := suggestKey(key, fields, tables); suggestion != "" {
+		return fmt.Errorf("unknown key '%s', did you mean %s?", key, suggestion)
+	}
+	return fmt.Errorf("unknown key '%s', see vx help config", key)
+}
+
+func firstKeyOf(table string) string {
+	for _, field := range Schema() {
+		if strings.HasPrefix(field.Key, table+".") {
+			return field.Key
+		}
+	}
+	return table
+}
+
+// ParseValue converts a value given on the command line to the TOML literal
+// for key: strings are quoted, integers and booleans checked, and arrays of
+// strings taken either as a TOML array or comma separated.
+func ParseValue(key, value string) (string, error) {
+	fields, _ := schemaIndex()
+	field, ok := fields[key]
+	if !ok {
+		return "", unknownKeyError(key)
+	}
+
+	switch field.Type {
+	case "integer":
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%s must be an integer, got '%s'", key, value)
+		}
+		return strconv.Itoa(n), nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false, got '%s'", key, value)
+		}
+		return strconv.FormatBool(b), nil
+	case "array of strings":
+		var items []string
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var parsed struct{ V []string }
+			if _, err := toml.Decode("V = "+value, &parsed); err != nil {
+				return "", fmt.Errorf("%s must be an array of strings: %v", key, err)
+			}
+			items = parsed.V
+		} else {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		return FormatValue(items), nil
+	default:
+		return FormatValue(value), nil
+	}
+}
+
+// SetValue sets key to value in the config file at path, keeping its
+// comments and layout (see setKey). The file is only written when the
+// result has no errors, which are returned as an *InvalidConfigError; the
+// warnings about the new file are returned.
+func SetValue(path, key, value string) ([]Problem, error) {
+	literal, err := ParseValue(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	updated := []byte(setKey(string(data), key, literal))
+
+	problems, err := CheckConfigData(path, updated)
+	if err != nil {
+		return nil, err
+	}
+	if err := configErrors(problems); err != nil {
+		return problems, err
+	}
+	return problems, WriteConfig(path, updated)
+}
+
+// WriteConfig replaces the config file at path with data through a
+// temporary file, so a failed write leaves the old file intact. The file
+// mode of the old file is kept.
+func WriteConfig(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vanish-*.toml")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// setKey returns data with key set to the TOML literal, keeping comments
+// and layout. The value of a line setting the key in its table is
+// replaced, keeping a trailing comment; otherwise a commented-out line for
+// the key in its table (as in the default file) is uncommented; otherwise
+// the key is added after the last key of its table, or in a new table at
+// the end.
+func setKey(data, key, literal string) string {
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+
+	current := ""
+	insertAt, commented := -1, -1
+	for offset := 0; offset < len(data); {
+		end := strings.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += offset
+		}
+		line := data[offset:end]
+		trimmed := strings.TrimSpace(line)
+		next := end + 1
+
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			if close := strings.Index(trimmed, "]"); close > 0 {
+				current = normalizeKey(strings.Trim(trimmed[:close], "[ "))
+				if current == table {
+					insertAt = min(next, len(data))
+				}
+			}
+		case current != table || trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			uncommented := strings.TrimSpace(trimmed[1:])
+			if commented < 0 && lineKey(uncommented) == name && strings.Contains(uncommented, "=") {
+				commented = offset
+			}
+		case strings.Contains(trimmed, "=") && lineKey(trimmed) == name:
+			eq := offset + strings.Index(line, "=")
+			start := eq + 1
+			for start < len(data) && (data[start] == ' ' || data[start] == '\t') {
+				start++
+			}
+			return data[:start] + literal + data[start+valueLength(data[start:]):]
+		default:
+			// Skip the rest of a value spanning lines
+			if eq := strings.Index(line, "="); eq >= 0 {
+				start := offset + eq + 1
+				for start < len(data) && (data[start] == ' ' || data[start] == '\t') {
+					start++
+				}
+				if valueEnd := start + valueLength(data[start:]); valueEnd > end {
+					if next = strings.IndexByte(data[valueEnd:], '\n'); next < 0 {
+						next = len(data)
+					} else {
+						next += valueEnd + 1
+					}
+				}
+			}
+			insertAt = min(next, len(data))
+		}
+		offset = next
+	}
+
+	if commented >= 0 {
+		// "# primary   = "#3B82F6"  # Main accent color" keeps its comment
+		hash := commented + strings.Index(data[commented:], "#")
+		rest := strings.TrimPrefix(data[hash+1:], " ")
+		uncommented := data[:commented] + data[commented:hash] + rest
+		return setKey(uncommented, key, literal)
+	}
+
+	entry := name + " = " + literal + "\n"
+	if insertAt >= 0 {
+		if insertAt > 0 && data[insertAt-1] != '\n' {
+			entry = "\n" + entry
+		}
+		return data[:insertAt] + entry + data[insertAt:]
+	}
+	if data != "" && !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	if data != "" {
+		data += "\n"
+	}
+	return data + "[" + table + "]\n" + entry
+}
+
+// commentKey returns data with the line setting key in its table
+// commented out, the way the default file lists settings left at their
+// default. Only values on a single line are handled.
+func commentKey(data, key string) string {
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+
+	current := ""
+	lines := strings.SplitAfter(data, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			if close := strings.Index(trimmed, "]"); close > 0 {
+				current = normalizeKey(strings.Trim(trimmed[:close], "[ "))
+			}
+		case current == table && !strings.HasPrefix(trimmed, "#") && strings.Contains(trimmed, "=") && lineKey(trimmed) == name:
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			lines[i] = indent + "# " + line[len(indent):]
+			return strings.Join(lines, "")
+		}
+	}
+	return data
+}
+
+// lineKey returns the key a "key = value" line sets.
+func lineKey(line string) string {
+	name, _, _ := strings.Cut(line, "=")
+	return normalizeKey(name)
+}
+
+// valueLength returns the length of the TOML value s starts with. Arrays
+// may span lines, their comments included.
+func valueLength(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\'':
+			i = closingQuote(s, i)
+			if depth == 0 {
+				return min(i+1, len(s))
+			}
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth <= 0 {
+				return i + 1
+			}
+		case c == '#' && depth > 0:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case depth == 0 && (c == ' ' || c == '\t' || c == '#' || c == '\n' || c == '\r'):
+			return i
+		}
+	}
+	return len(s)
+}
+
+// closingQuote returns the index of the quote closing the string opened at
+// s[open], skipping escapes in basic strings.
+func closingQuote(s string, open int) int {
+	quote := s[open]
+	for i := open + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		case s[i] == '\n':
+			return i - 1
+		}
+	}
+	return len(s) - 1
+}
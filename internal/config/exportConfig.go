@@ -3,172 +3,162 @@
 
 package config
 
-// import (
-// 	"fmt"
-// 	"os"
-// 	"path/filepath"
-// 	"time"
-// 	"github.com/BurntSushi/toml"
-// 	"vanish/internal/types"
-// )
-
-// func ExportConfig(exportPath string) error {
-// 	homeDir, err := os.UserHomeDir()
-// 	if err != nil {
-// 		return fmt.Errorf("failed to get home directory: %v", err)
-// 	}
-
-// 	// Path to current config (fix directory consistency)
-// 	configDir := filepath.Join(homeDir, ".config", "vanish")
-// 	configPath := filepath.Join(configDir, "vanish.toml")
-
-// 	// Check if config file exists
-// 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-// 		return fmt.Errorf("config file does not exist: %s", configPath)
-// 	}
-
-// 	// Create backup in the same config directory
-// 	backupPath := filepath.Join(configDir, "vanish-bck.toml")
-// 	if err := copyConfigFile(configPath, backupPath); err != nil {
-// 		return fmt.Errorf("failed to create backup: %v", err)
-// 	}
-// 	fmt.Printf("Config backed up to: %s\n", backupPath)
-
-// 	// Determine export path
-// 	finalExportPath := exportPath
-
-// 	// Check if exportPath is a directory
-// 	if info, err := os.Stat(exportPath); err == nil && info.IsDir() {
-// 		// Append default filename if directory provided
-// 		finalExportPath = filepath.Join(exportPath, "vanish.toml")
-// 	} else {
-// 		// Make sure parent directory exists
-// 		parentDir := filepath.Dir(exportPath)
-// 		if err := os.MkdirAll(parentDir, 0755); err != nil {
-// 			return fmt.Errorf("failed to create export directory: %v", err)
-// 		}
-// 	}
-
-// 	// Copy config file to export path
-// 	if err := copyConfigFile(configPath, finalExportPath); err != nil {
-// 		return fmt.Errorf("failed to export config: %v", err)
-// 	}
-
-// 	fmt.Printf("Configuration exported successfully to: %s\n", finalExportPath)
-// 	return nil
-// }
-
-// // importConfig imports configuration from a specified file
-// func ImportConfig(importPath string) error {
-// 	// Check if import file exists
-// 	if _, err := os.Stat(importPath); os.IsNotExist(err) {
-// 		return fmt.Errorf("import file does not exist: %s", importPath)
-// 	}
-
-// 	// Get current config path (fix directory consistency)
-// 	homeDir, err := os.UserHomeDir()
-// 	if err != nil {
-// 		return fmt.Errorf("failed to get home directory: %v", err)
-// 	}
-
-// 	configPath := filepath.Join(homeDir, ".config", "vanish", "vanish.toml") // Fixed to match ExportConfig
-// 	configDir := filepath.Dir(configPath)
-
-// 	// Create config directory if it doesn't exist
-// 	if err := os.MkdirAll(configDir, 0755); err != nil {
-// 		return fmt.Errorf("failed to create config directory: %v", err)
-// 	}
-
-// 	// Create backup of existing config if it exists
-// 	if _, err := os.Stat(configPath); err == nil {
-// 		timestamp := time.Now().Format("2006-01-02-15-04-05")
-// 		backupPath := filepath.Join(configDir, fmt.Sprintf("vanish.toml.backup-%s", timestamp))
-
-// 		// Copy current config to backup
-// 		if err := copyConfigFile(configPath, backupPath); err != nil {
-// 			return fmt.Errorf("failed to create config backup: %v", err)
-// 		}
-// 		fmt.Printf("Existing config backed up to: %s\n", backupPath)
-// 	}
-
-// 	// Test the import file by trying to parse it
-// 	var testConfig types.Config
-// 	if _, err := toml.DecodeFile(importPath, &testConfig); err != nil {
-// 		return fmt.Errorf("invalid config file format: %v", err)
-// 	}
-
-// 	// Copy import file to config location
-// 	if err := copyConfigFile(importPath, configPath); err != nil {
-// 		return fmt.Errorf("failed to import config: %v", err)
-// 	}
-
-// 	fmt.Printf("Configuration imported successfully from: %s\n", importPath)
-// 	fmt.Printf("New config location: %s\n", configPath)
-
-// 	// Validate the imported config by loading it
-// 	if _, err := LoadConfig(); err != nil {
-// 		return fmt.Errorf("imported config is invalid: %v", err)
-// 	}
-
-// 	fmt.Println("Imported configuration validated successfully!")
-// 	return nil
-// }
-
-// // Enhanced version with better error handling and options
-// func ExportConfigWithOptions(exportPath string, createBackup bool, backupName string) error {
-// 	homeDir, err := os.UserHomeDir()
-// 	if err != nil {
-// 		return fmt.Errorf("failed to get home directory: %v", err)
-// 	}
-
-// 	configDir := filepath.Join(homeDir, ".config", "vanish")
-// 	configPath := filepath.Join(configDir, "vanish.toml")
-
-// 	// Check if config file exists
-// 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-// 		return fmt.Errorf("config file does not exist: %s", configPath)
-// 	}
-
-// 	// Create backup if requested
-// 	if createBackup {
-// 		if backupName == "" {
-// 			backupName = "vanish-bck.toml"
-// 		}
-// 		backupPath := filepath.Join(configDir, backupName)
-
-// 		if err := copyConfigFile(configPath, backupPath); err != nil {
-// 			return fmt.Errorf("failed to create backup: %v", err)
-// 		}
-// 		fmt.Printf("Config backed up to: %s\n", backupPath)
-// 	}
-
-// 	// Handle export path
-// 	finalExportPath := exportPath
-// 	if info, err := os.Stat(exportPath); err == nil && info.IsDir() {
-// 		finalExportPath = filepath.Join(exportPath, "vanish.toml")
-// 	} else {
-// 		if err := os.MkdirAll(filepath.Dir(exportPath), 0755); err != nil {
-// 			return fmt.Errorf("failed to create export directory: %v", err)
-// 		}
-// 	}
-
-// 	// Export the config
-// 	if err := copyConfigFile(configPath, finalExportPath); err != nil {
-// 		return fmt.Errorf("failed to export config: %v", err)
-// 	}
-
-// 	fmt.Printf("Configuration exported successfully to: %s\n", finalExportPath)
-// 	return nil
-// }
-
-// // Helper function to copy config files
-// func copyConfigFile(src, dst string) error {
-// 	// Read source file
-// 	data, err := os.ReadFile(src)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	// Write to destination
-// 	return os.WriteFile(dst, data, 0644)
-// }
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"vanish/internal/helpers"
+)
+
+// --- Export, Import and Backups ---
+
+// maxConfigBackups is how many backups BackupConfig keeps, the oldest are
+// removed beyond it.
+const maxConfigBackups = 10
+
+// backupPrefix starts the name of every backup next to vanish.toml.
+const backupPrefix = "vanish.toml.backup-"
+
+// ExportConfig returns the contents of the config file. withTheme writes
+// the colors and progress settings of the theme in use into [ui.colors]
+// and [ui.progress], so the export looks the same with any built-in theme.
+func ExportConfig(withTheme bool) ([]byte, error) {
+	data, err := os.ReadFile(helpers.GetConfigPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if !withTheme {
+		return data, nil
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	exported := string(data)
+	var fields []SchemaField
+	walkSchema(reflect.ValueOf(config.UI), "ui.", &fields)
+	for _, field := range fields {
+		if strings.HasPrefix(field.Key, "ui.colors.") || strings.HasPrefix(field.Key, "ui.progress.") {
+			exported = setKey(exported, field.Key, FormatValue(field.Default))
+		}
+	}
+	return []byte(exported), nil
+}
+
+// SettingChange is a key whose value differs between two config files.
+type SettingChange struct {
+	Key string
+	Old string // TOML literal
+	New string
+}
+
+// DiffSettings returns the keys set differently by two config files, each
+// merged over the defaults, in schema order. Comments and layout are not
+// compared. Files that do not decode are compared as far as they do.
+func DiffSettings(oldData, newData []byte) []SettingChange {
+	homeDir, _ := os.UserHomeDir()
+	oldConfig, _, _ := decodeConfig("", oldData, homeDir)
+	newConfig, _, _ := decodeConfig("", newData, homeDir)
+
+	var oldFields, newFields []SchemaField
+	walkSchema(reflect.ValueOf(oldConfig), "", &oldFields)
+	walkSchema(reflect.ValueOf(newConfig), "", &newFields)
+
+	var changes []SettingChange
+	for i := range oldFields {
+		oldValue, newValue := FormatValue(oldFields[i].Default), FormatValue(newFields[i].Default)
+		if oldValue != newValue {
+			changes = append(changes, SettingChange{Key: oldFields[i].Key, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// BackupConfig copies the config file at path to a timestamped backup next
+// to it, e.g. vanish.toml.backup-2026-10-18T14-03-11.520, and removes the
+// oldest backups beyond maxConfigBackups. It returns the backup's path, ""
+// when there is no config file to back up.
+func BackupConfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	backup := filepath.Join(filepath.Dir(path), backupPrefix+time.Now().Format("2006-01-02T15-04-05.000"))
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up config: %w", err)
+	}
+
+	backups, err := ConfigBackups(path)
+	if err != nil {
+		return backup, err
+	}
+	for len(backups) > maxConfigBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return backup, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return backup, nil
+}
+
+// ConfigBackups returns the backups of the config file at path, oldest
+// first.
+func ConfigBackups(path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasPrefix(entry.Name(), backupPrefix) {
+			backups = append(backups, filepath.Join(filepath.Dir(path), entry.Name()))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// BackupTime returns when a backup was taken, from its name.
+func BackupTime(backup string) (time.Time, error) {
+	stamp := strings.TrimPrefix(filepath.Base(backup), backupPrefix)
+	return time.ParseInLocation("2006-01-02T15-04-05.000", stamp, time.Local)
+}
+
+// FindBackup returns the backup of the config file at path named by name:
+// its file name, the timestamp in it, or a path. An empty name is the
+// newest backup.
+func FindBackup(path, name string) (string, error) {
+	backups, err := ConfigBackups(path)
+	if err != nil {
+		return "", err
+	}
+	if len(backups) == 0 {
+		return "", fmt.Errorf("no backups of %s yet", path)
+	}
+	if name == "" {
+		return backups[len(backups)-1], nil
+	}
+
+	for _, backup := range backups {
+		base := filepath.Base(backup)
+		if name == backup || name == base || backupPrefix+name == base {
+			return backup, nil
+		}
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+	return "", fmt.Errorf("no backup named '%s', see vx config backups", name)
+}
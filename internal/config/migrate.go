@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// --- Migration ---
+
+// The directories the default config file of earlier versions set,
+// relative to HOME. They are in ~/.cache, which cache cleaners empty.
+const (
+	legacyCacheDir = ".cache/vanish"
+	legacyLogDir   = ".cache/vanish/logs"
+)
+
+// migrateLegacyDirs moves the store and the logs from where earlier
+// versions kept them, ~/.cache/vanish, to the XDG data and state
+// directories, and rewrites the cache paths in the index. A directory is
+// moved when the config file sets it to the old default or not at all,
+// the old one exists and the new one is not in use yet. The old default
+// lines are then commented out, after backing up the file, so the
+// migration runs once. A failed move leaves the file alone and vx keeps
+// using the old directory. It returns the config file's contents, with
+// unset directories that could not be moved set to the old ones.
+func migrateLegacyDirs(configPath string, data []byte, homeDir string) []byte {
+	if dryRun {
+		return keepLegacyDirs(data, homeDir)
+	}
+
+	settings, err := decodeDirSettings(data)
+	if err != nil {
+		return data // Load reports it
+	}
+	defaults := DefaultConfig(homeDir)
+
+	// A failed move leaves an unset directory at the new default, so the
+	// returned contents point it back at the old one
+	var migrated []string
+	var kept [][2]string // key and old directory
+	withKept := func(updated string) []byte {
+		for _, setting := range kept {
+			updated = setKey(updated, setting[0], FormatValue(setting[1]))
+		}
+		return []byte(updated)
+	}
+
+	if legacy, ok := legacySetting(settings.Logging.Directory, legacyLogDir); ok {
+		old := filepath.Join(homeDir, legacyLogDir)
+		use, _, err := moveLegacyDir(old, defaults.Logging.Directory, helpers.LogFileName, "logs")
+		if use && legacy {
+			migrated = append(migrated, "logging.directory")
+		} else if err != nil && !legacy {
+			kept = append(kept, [2]string{"logging.directory", old})
+		}
+	}
+	if legacy, ok := legacySetting(settings.Cache.Directory, legacyCacheDir); ok {
+		old := filepath.Join(homeDir, legacyCacheDir)
+		use, moved, err := moveLegacyDir(old, defaults.Cache.Directory, "index.json", "store")
+		if moved {
+			if err := rewriteCachePaths(old, defaults); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not rewrite the index after moving the store: %v\n", err)
+			}
+		}
+		if use && legacy {
+			migrated = append(migrated, "cache.directory")
+		} else if err != nil && !legacy {
+			kept = append(kept, [2]string{"cache.directory", old})
+		}
+	}
+	if len(migrated) == 0 {
+		return withKept(string(data))
+	}
+
+	updated := string(data)
+	for _, key := range migrated {
+		updated = commentKey(updated, key)
+	}
+	backup, err := BackupConfig(configPath)
+	if err == nil {
+		err = WriteConfig(configPath, []byte(updated))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update %s, remove %s from it: %v\n", configPath, strings.Join(migrated, " and "), err)
+		return withKept(updated)
+	}
+	fmt.Fprintf(os.Stderr, "Commented out the old %s in %s, the previous file is %s\n", strings.Join(migrated, " and "), configPath, backup)
+	return withKept(updated)
+}
+
+// dirSettings are the directory settings of a config file, nil when unset.
+type dirSettings struct {
+	Cache   struct{ Directory *string }
+	Logging struct{ Directory *string }
+}
+
+func decodeDirSettings(data []byte) (dirSettings, error) {
+	var settings dirSettings
+	_, err := toml.Decode(string(data), &settings)
+	return settings, err
+}
+
+// keepLegacyDirs returns data pointing at the old directories that
+// migrateLegacyDirs would move, so a dry run or Peek sees the store where
+// it is without moving it.
+func keepLegacyDirs(data []byte, homeDir string) []byte {
+	settings, err := decodeDirSettings(data)
+	if err != nil {
+		return data
+	}
+	defaults := DefaultConfig(homeDir)
+	updated := string(data)
+	if settings.Logging.Directory == nil {
+		old := filepath.Join(homeDir, legacyLogDir)
+		if legacyDirPending(old, defaults.Logging.Directory, helpers.LogFileName) {
+			updated = setKey(updated, "logging.directory", FormatValue(old))
+		}
+	}
+	if settings.Cache.Directory == nil {
+		old := filepath.Join(homeDir, legacyCacheDir)
+		if legacyDirPending(old, defaults.Cache.Directory, "index.json") {
+			updated = setKey(updated, "cache.directory", FormatValue(old))
+		}
+	}
+	return []byte(updated)
+}
+
+// legacyDirPending reports whether old exists and dir does not hold
+// marker, the file showing it is in use, so old is still to be moved.
+func legacyDirPending(old, dir, marker string) bool {
+	if _, err := os.Stat(old); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(dir, marker))
+	return os.IsNotExist(err)
+}
+
+// legacySetting reports whether a directory setting may be migrated: ok
+// when it is unset or the old default, legacy when the file sets the old
+// default.
+func legacySetting(value *string, legacyDefault string) (legacy, ok bool) {
+	if value == nil {
+		return false, true
+	}
+	return *value == legacyDefault, *value == legacyDefault
+}
+
+// moveLegacyDir moves old to dir unless old does not exist or dir already
+// holds marker, the file showing it is in use. It reports whether dir is
+// the one to use now, because old was moved or never existed, and whether
+// it moved old. err is set when the move failed.
+func moveLegacyDir(old, dir, marker, what string) (use, moved bool, err error) {
+	if _, err := os.Stat(old); os.IsNotExist(err) {
+		return true, false, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+		return false, false, nil
+	}
+
+	// An empty directory from a run of this version is in the way of rename
+	os.Remove(dir)
+	err = os.MkdirAll(filepath.Dir(dir), 0755)
+	if err == nil {
+		err = helpers.MoveDirectory(old, dir)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not move the vanish %s from %s to %s, keeping it: %v\n", what, old, dir, err)
+		return false, false, err
+	}
+	fmt.Fprintf(os.Stderr, "Moved the vanish %s from %s to %s\n", what, old, dir)
+	return true, true, nil
+}
+
+// rewriteCachePaths points the cache paths of the index in the moved store
+// at the new directory.
+func rewriteCachePaths(old string, config types.Config) error {
+	index, err := helpers.LoadIndex(config)
+	if err != nil {
+		return err
+	}
+	for i, item := range index.Items {
+		if rest, ok := strings.CutPrefix(item.CachePath, old+string(filepath.Separator)); ok {
+			index.Items[i].CachePath = filepath.Join(config.Cache.Directory, rest)
+		}
+	}
+	return helpers.SaveIndex(index, config)
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"vanish/internal/types"
+)
+
+// --- Overrides ---
+
+// Override is a setting given outside the config file, by an environment
+// variable or --set. Overrides take precedence over the file: --set over
+// the environment, the environment over the file, the file over the
+// defaults.
+type Override struct {
+	Key     string
+	Literal string // TOML literal, see ParseValue
+	Source  string // where it was given, e.g. "VANISH_DAYS" or "--set"
+}
+
+// envAliases are short environment names for the most used keys, besides
+// the VANISH_<TABLE>_<KEY> every key has.
+var envAliases = map[string]string{
+	"cache.directory":   "VANISH_CACHE_DIR",
+	"cache.days":        "VANISH_DAYS",
+	"cache.no_confirm":  "VANISH_NO_CONFIRM",
+	"logging.directory": "VANISH_LOG_DIR",
+	"logging.level":     "VANISH_LOG_LEVEL",
+	"ui.theme":          "VANISH_THEME",
+}
+
+// flagOverrides holds the --set values of this run, see SetFlagOverrides.
+var flagOverrides []string
+
+// dryRun keeps Load from writing anything, see SetDryRun.
+var dryRun bool
+
+// SetDryRun makes every later Load leave the disk alone: no default config
+// file is created and old directories are used where they are instead of
+// being migrated.
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+}
+
+// SetFlagOverrides records the key=value pairs given with --set, applied by
+// every later Load.
+func SetFlagOverrides(values []string) {
+	flagOverrides = values
+}
+
+// EnvNames returns the environment variables overriding key, the short
+// alias first when it has one, e.g. VANISH_DAYS and VANISH_CACHE_DAYS.
+func EnvNames(key string) []string {
+	full := "VANISH_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if alias, ok := envAliases[key]; ok {
+		return []string{alias, full}
+	}
+	return []string{full}
+}
+
+// Overrides returns the settings given by environment variables and --set,
+// in the order they apply. Empty variables are ignored. A value that does
+// not fit its key is an error naming where it was given.
+func Overrides() ([]Override, error) {
+	var overrides []Override
+	for _, field := range Schema() {
+		// The full name wins over the alias
+		for _, name := range EnvNames(field.Key) {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+			literal, err := ParseValue(field.Key, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			overrides = append(overrides, Override{Key: field.Key, Literal: literal, Source: name})
+		}
+	}
+
+	for _, set := range flagOverrides {
+		key, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set %s: expected key=value", set)
+		}
+		key = strings.TrimSpace(key)
+		literal, err := ParseValue(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("--set %s: %w", set, err)
+		}
+		overrides = append(overrides, Override{Key: key, Literal: literal, Source: "--set"})
+	}
+	return overrides, nil
+}
+
+// applyOverrides decodes data with the overrides written over it. The
+// problems of overridden keys are the overrides' own, reported under
+// their source, the file's problems with those keys no longer apply.
+func applyOverrides(path string, data []byte, fileProblems []Problem, overrides []Override, homeDir string) (types.Config, []Problem, error) {
+	text := string(data)
+	sources := make(map[string]string)
+	for _, o := range overrides {
+		text = setKey(text, o.Key, o.Literal)
+		sources[o.Key] = o.Source
+	}
+
+	config, problems, err := decodeConfig(path, []byte(text), homeDir)
+	if err != nil {
+		return config, nil, err
+	}
+
+	var merged []Problem
+	for _, p := range fileProblems {
+		if _, ok := sources[p.Key]; !ok {
+			merged = append(merged, p)
+		}
+	}
+	for _, p := range problems {
+		if source, ok := sources[p.Key]; ok {
+			p.File, p.Line = source, 0
+			merged = append(merged, p)
+		}
+	}
+	return config, merged, nil
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaField describes one key of the config file.
+type SchemaField struct {
+	Key     string // dotted TOML key, e.g. "cache.days"
+	Type    string // TOML type: "string", "integer", "boolean" or "array of strings"
+	Default any
+	Doc     string
+}
+
+// Schema lists every key of types.Config in declaration order, with the
+// defaults used when the key is missing. Paths are shown relative to "~".
+func Schema() []SchemaField {
+	defaults := DefaultConfig("~")
+	defaults.UI = GetDefaultThemes()["default"].UI
+
+	var fields []SchemaField
+	walkSchema(reflect.ValueOf(defaults), "", &fields)
+	return fields
+}
+
+func walkSchema(v reflect.Value, prefix string, fields *[]SchemaField) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+
+		if field.Type.Kind() == reflect.Struct {
+			walkSchema(v.Field(i), key+".", fields)
+			continue
+		}
+
+		*fields = append(*fields, SchemaField{
+			Key:     key,
+			Type:    tomlTypeName(field.Type),
+			Default: v.Field(i).Interface(),
+			Doc:     field.Tag.Get("doc"),
+		})
+	}
+}
+
+func tomlTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice:
+		return "array of " + tomlTypeName(t.Elem()) + "s"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// FormatValue renders a config value the way it is written in TOML.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,468 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"vanish/internal/helpers"
+	"vanish/internal/types"
+)
+
+// --- Validation ---
+
+// Problem is something wrong in the config file. Errors stop vx from
+// running, warnings are settings that are ignored or fall back to a default.
+type Problem struct {
+	File    string
+	Line    int    // 0 when the line is not known
+	Key     string // dotted key, "" for syntax errors
+	Message string
+	Warning bool
+}
+
+func (p Problem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	if p.Key == "" {
+		return location + ": " + p.Message
+	}
+	return fmt.Sprintf("%s: %s: %s", location, p.Key, p.Message)
+}
+
+// InvalidConfigError is returned by LoadConfig when the config file has
+// errors. It lists the errors only, vx config check shows the warnings too.
+type InvalidConfigError struct {
+	Problems []Problem
+}
+
+func (e *InvalidConfigError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid config file, vx config check lists every problem:")
+	for _, p := range e.Problems {
+		if !p.Warning {
+			b.WriteString("\n  " + p.String())
+		}
+	}
+	return b.String()
+}
+
+// configErrors returns an *InvalidConfigError when any of problems is an
+// error, nil otherwise.
+func configErrors(problems []Problem) error {
+	for _, p := range problems {
+		if !p.Warning {
+			return &InvalidConfigError{Problems: problems}
+		}
+	}
+	return nil
+}
+
+// CheckConfig returns every problem in the config file at path, sorted by
+// line, then those of the environment and --set overrides. An error is
+// returned only when the file cannot be read or an override does not fit
+// its key.
+func CheckConfig(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := Overrides()
+	if err != nil {
+		return nil, err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	_, problems, err := decodeConfig(path, data, homeDir)
+	if err == nil && len(overrides) > 0 {
+		_, problems, err = applyOverrides(path, data, problems, overrides, homeDir)
+	}
+	return problems, err
+}
+
+// CheckConfigData is CheckConfig for the contents of a config file not yet
+// written to path.
+func CheckConfigData(path string, data []byte) ([]Problem, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	_, problems, err := decodeConfig(path, data, homeDir)
+	return problems, err
+}
+
+// deprecatedKeys maps keys of earlier versions to the keys replacing them.
+// A deprecated key still applies when its replacement is not set, with a
+// warning.
+var deprecatedKeys = map[string]string{
+	"ui.no_confirm": "cache.no_confirm", // in [ui] in the default file of earlier versions
+}
+
+// decodeConfig decodes data over the defaults and checks it: syntax,
+// unknown keys, the type of every key and the values. The values are only
+// checked when the types are right, since decoding stops at the first
+// mismatch.
+func decodeConfig(path string, data []byte, homeDir string) (types.Config, []Problem, error) {
+	config := DefaultConfig(homeDir)
+	var tree map[string]any
+	if _, err := toml.Decode(string(data), &tree); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return config, []Problem{{File: path, Line: parseErr.Position.Line, Message: parseErr.Message}}, nil
+		}
+		return config, nil, fmt.Errorf("error parsing config file: %v", err)
+	}
+
+	fields, tables := schemaIndex()
+	c := &checker{file: path, lines: keyLines(string(data)), fields: fields, tables: tables}
+	c.checkTypes(tree, "")
+	if configErrors(c.problems) == nil {
+		text := string(data)
+		for old, key := range deprecatedKeys {
+			value, ok := treeValue(tree, old)
+			if _, set := treeValue(tree, key); ok && !set {
+				text = setKey(text, key, FormatValue(value))
+			}
+		}
+		if _, err := toml.Decode(text, &config); err != nil {
+			return config, c.problems, fmt.Errorf("error parsing config file: %v", err)
+		}
+		c.checkValues(config, homeDir)
+	}
+
+	sort.SliceStable(c.problems, func(i, j int) bool { return c.problems[i].Line < c.problems[j].Line })
+	return config, c.problems, nil
+}
+
+type checker struct {
+	file     string
+	lines    map[string]int
+	fields   map[string]SchemaField
+	tables   map[string]bool
+	problems []Problem
+}
+
+func (c *checker) errorf(key, format string, args ...any) {
+	c.problems = append(c.problems, Problem{File: c.file, Line: c.line(key), Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) warnf(key, format string, args ...any) {
+	c.problems = append(c.problems, Problem{File: c.file, Line: c.line(key), Key: key, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// line returns the line key is set on, or that of the closest table
+// holding it.
+func (c *checker) line(key string) int {
+	for {
+		if line, ok := c.lines[key]; ok {
+			return line
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return 0
+		}
+		key = key[:i]
+	}
+}
+
+// checkTypes reports unknown keys and keys whose value has the wrong type.
+func (c *checker) checkTypes(tree map[string]any, prefix string) {
+	for name, value := range tree {
+		key := prefix + name
+		if replacement, ok := deprecatedKeys[key]; ok {
+			if want, got := c.fields[replacement].Type, tomlType(value); got != want {
+				c.errorf(key, "expected %s, got %s", want, got)
+			} else {
+				c.warnf(key, "deprecated, move it to %s", replacement)
+			}
+			continue
+		}
+		if field, ok := c.fields[key]; ok {
+			if got := tomlType(value); got != field.Type {
+				c.errorf(key, "expected %s, got %s", field.Type, got)
+			}
+			continue
+		}
+		if c.tables[key] {
+			if sub, ok := value.(map[string]any); ok {
+				c.checkTypes(sub, key+".")
+			} else {
+				c.errorf(key, "expected table, got %s", tomlType(value))
+			}
+			continue
+		}
+
+		if suggestion := suggestKey(key, c.fields, c.tables); suggestion != "" {
+			c.warnf(key, "unknown key, did you mean %s?", suggestion)
+		} else {
+			c.warnf(key, "unknown key")
+		}
+	}
+}
+
+// treeValue returns the value of a dotted key in a decoded TOML tree.
+func treeValue(tree map[string]any, key string) (any, bool) {
+	parts := strings.Split(key, ".")
+	for _, table := range parts[:len(parts)-1] {
+		sub, ok := tree[table].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		tree = sub
+	}
+	value, ok := tree[parts[len(parts)-1]]
+	return value, ok
+}
+
+// tomlType names the type of a decoded TOML value the way Schema does.
+func tomlType(value any) string {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "datetime"
+	case map[string]any:
+		return "table"
+	case []map[string]any:
+		return "array of tables"
+	case []any:
+		for _, elem := range v {
+			if _, ok := elem.(string); !ok {
+				return "array"
+			}
+		}
+		return "array of strings"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaIndex returns the Schema fields by key, and the tables holding
+// them, e.g. "ui" and "ui.colors".
+func schemaIndex() (map[string]SchemaField, map[string]bool) {
+	fields := make(map[string]SchemaField)
+	tables := make(map[string]bool)
+	for _, field := range Schema() {
+		fields[field.Key] = field
+		for table := field.Key; strings.Contains(table, "."); {
+			table = table[:strings.LastIndex(table, ".")]
+			tables[table] = true
+		}
+	}
+	return fields, tables
+}
+
+// suggestKey returns the known key or table closest to an unknown key:
+// one with the same name in another table, or one a typo or two away.
+func suggestKey(key string, fields map[string]SchemaField, tables map[string]bool) string {
+	var candidates []string
+	for known := range fields {
+		candidates = append(candidates, known)
+	}
+	for table := range tables {
+		candidates = append(candidates, table)
+	}
+	sort.Strings(candidates)
+
+	name := key[strings.LastIndex(key, ".")+1:]
+	for _, known := range candidates {
+		if fields[known].Key != "" && known[strings.LastIndex(known, ".")+1:] == name {
+			return known
+		}
+	}
+
+	best, bestDistance := "", 3
+	for _, known := range candidates {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// checkValues reports values out of range, unknown names and paths vx
+// must not use.
+func (c *checker) checkValues(config types.Config, homeDir string) {
+	c.checkDirectory("cache.directory", config.Cache.Directory, homeDir, true)
+	if config.Cache.Days < 1 {
+		c.errorf("cache.days", "must be at least 1, got %d", config.Cache.Days)
+	}
+
+	if config.Logging.Directory != "" {
+		c.checkDirectory("logging.directory", config.Logging.Directory, homeDir, false)
+	}
+	if config.Logging.Format != "json" && config.Logging.Format != "text" {
+		c.warnf("logging.format", "unknown format %q, json is used (json or text)", config.Logging.Format)
+	}
+	if !helpers.ValidLogLevel(config.Logging.Level) {
+		c.warnf("logging.level", "unknown level %q, info is used (debug, info, warn or error)", config.Logging.Level)
+	}
+	for key, value := range map[string]int{
+		"logging.max_size":          config.Logging.MaxSize,
+		"logging.max_files":         config.Logging.MaxFiles,
+		"logging.max_age":           config.Logging.MaxAge,
+		"hooks.timeout":             config.Hooks.Timeout,
+		"notifications.min_seconds": config.Notifications.MinSeconds,
+	} {
+		if value < 0 {
+			c.errorf(key, "must be 0 or more, got %d", value)
+		}
+	}
+
+	for i, pattern := range config.Safety.Protected {
+		if strings.TrimSpace(pattern) == "" {
+			c.warnf("safety.protected", "entry %d is empty and never matches", i+1)
+			continue
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := filepath.Match(segment, ""); err != nil {
+				c.errorf("safety.protected", "invalid pattern %q: %v", pattern, err)
+				break
+			}
+		}
+	}
+
+	themes := GetDefaultThemes()
+	if _, ok := themes[config.UI.Theme]; !ok && config.UI.Theme != "" {
+		names := make([]string, 0, len(themes))
+		for name := range themes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		c.warnf("ui.theme", "unknown theme %q, default is used (%s)", config.UI.Theme, strings.Join(names, ", "))
+	}
+
+	colors := config.UI.Colors
+	for key, color := range map[string]string{
+		"ui.colors.primary":   colors.Primary,
+		"ui.colors.secondary": colors.Secondary,
+		"ui.colors.success":   colors.Success,
+		"ui.colors.warning":   colors.Warning,
+		"ui.colors.error":     colors.Error,
+		"ui.colors.text":      colors.Text,
+		"ui.colors.muted":     colors.Muted,
+		"ui.colors.border":    colors.Border,
+		"ui.colors.highlight": colors.Highlight,
+	} {
+		if color == "" || hexColor.MatchString(color) {
+			continue
+		}
+		if n, err := strconv.Atoi(color); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		c.warnf(key, "invalid color %q, use #RGB, #RRGGBB or an ANSI color number 0-255", color)
+	}
+
+	switch config.UI.Progress.Style {
+	case "", "gradient", "solid", "rainbow":
+	default:
+		c.warnf("ui.progress.style", "unknown style %q, gradient is used (gradient, solid or rainbow)", config.UI.Progress.Style)
+	}
+}
+
+// checkDirectory reports a directory setting that is a file, the root
+// directory, or for the cache (which vx clear empties) the home directory
+// or one of its parents.
+func (c *checker) checkDirectory(key, value, homeDir string, emptied bool) {
+	dir := filepath.Clean(helpers.ExpandPath(value))
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		c.errorf(key, "%s is not a directory", dir)
+		return
+	}
+	if dir == "/" {
+		c.errorf(key, "must not be the root directory")
+		return
+	}
+	if !emptied {
+		return
+	}
+	if rel, err := filepath.Rel(dir, homeDir); err == nil && (rel == "." || !strings.HasPrefix(rel, "..")) {
+		c.errorf(key, "must not be your home directory or one of its parents, vx clear empties it")
+	}
+}
+
+// keyLines maps the dotted keys and tables of a TOML document to the line
+// they are defined on. It only understands what vanish.toml uses: [table]
+// headers and key = value lines, with bare or quoted keys.
+func keyLines(data string) map[string]int {
+	lines := make(map[string]int)
+	table := ""
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || line[0] == '#':
+		case line[0] == '[':
+			if end := strings.Index(line, "]"); end > 0 {
+				table = normalizeKey(strings.Trim(line[:end], "[ "))
+				if _, seen := lines[table]; !seen {
+					lines[table] = i + 1
+				}
+			}
+		default:
+			name, _, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key := normalizeKey(name)
+			if table != "" {
+				key = table + "." + key
+			}
+			if _, seen := lines[key]; !seen {
+				lines[key] = i + 1
+			}
+		}
+	}
+	return lines
+}
+
+func normalizeKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+//go:build !linux && !darwin
+
+package helpers
+
+import (
+	"io/fs"
+	"time"
+)
+
+// accessTime falls back to the modification time where the access time is
+// not exposed.
+func accessTime(info fs.FileInfo) time.Time {
+	return info.ModTime()
+}
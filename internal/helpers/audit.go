@@ -0,0 +1,296 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"vanish/internal/types"
+)
+
+// --- Audit Trail ---
+
+// auditStateFile remembers the last record of the chain, so a record can
+// be chained without reading the log, and so records cut from the end of
+// the log are noticed. It also remembers the last record of every rotated
+// log, so that once older logs are pruned, records cut from the start of
+// the oldest remaining log are noticed too.
+const auditStateFile = LogFileName + ".chain"
+
+type auditState struct {
+	Seq       int64        `json:"seq"`
+	Hash      string       `json:"hash"`
+	Rotations []auditState `json:"rotations,omitempty"` // last records of the rotated logs, oldest first
+}
+
+// auditHashSuffix matches the hash field that ends every audited line.
+var auditHashSuffix = regexp.MustCompile(`,"hash":"([0-9a-f]{64})"}$`)
+
+func loadAuditState(logDir string) (auditState, error) {
+	var state auditState
+	data, err := os.ReadFile(filepath.Join(logDir, auditStateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read audit state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse audit state: %w", err)
+	}
+	return state, nil
+}
+
+func saveAuditState(logDir string, state auditState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(logDir, auditStateFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save audit state: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(logDir, auditStateFile))
+}
+
+// chainEntry numbers entry after the last audited record and links it to
+// that record's hash. It returns the new chain state and the JSON line,
+// which ends in the entry's own hash: a sha256 of the line without the
+// hash field. The caller holds the log lock.
+func chainEntry(logDir string, entry types.LogEntry) (auditState, string, error) {
+	state, err := loadAuditState(logDir)
+	if err != nil {
+		return state, "", err
+	}
+
+	entry.Seq = state.Seq + 1
+	entry.PrevHash = state.Hash
+	entry.Hash = ""
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return state, "", fmt.Errorf("failed to encode log entry: %w", err)
+	}
+
+	hash := auditHash(data)
+	line := fmt.Sprintf(`%s,"hash":"%s"}`, data[:len(data)-1], hash)
+	return auditState{Seq: entry.Seq, Hash: hash, Rotations: state.Rotations}, line, nil
+}
+
+// recordAuditRotation notes the last record of the log that was just
+// rotated, where the next log legitimately starts. Only as many as there
+// are rotated logs, plus the current one, are kept. The caller holds the
+// log lock.
+func recordAuditRotation(logDir string) error {
+	state, err := loadAuditState(logDir)
+	if err != nil || state.Seq == 0 {
+		return err
+	}
+	state.Rotations = append(state.Rotations, auditState{Seq: state.Seq, Hash: state.Hash})
+	if keep := len(rotatedLogs(logDir)) + 1; len(state.Rotations) > keep {
+		state.Rotations = state.Rotations[len(state.Rotations)-keep:]
+	}
+	return saveAuditState(logDir, state)
+}
+
+// startsAfterRotation reports whether entry is the first record of a log
+// started by a recorded rotation.
+func (s auditState) startsAfterRotation(entry types.LogEntry) bool {
+	for _, r := range s.Rotations {
+		if entry.Seq == r.Seq+1 && entry.PrevHash == r.Hash {
+			return true
+		}
+	}
+	return false
+}
+
+func auditHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentHash returns a sha256 of what is at path: the contents of a file,
+// the target of a symlink, or for a directory every entry's relative path,
+// type and content in walk order.
+func ContentHash(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+
+	hasher := sha256.New()
+	if !info.IsDir() {
+		if err := hashEntry(hasher, path, info.Mode()); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hasher.Sum(nil)), nil
+	}
+
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(path, p)
+		fmt.Fprintf(hasher, "%s %s\n", d.Type().String(), rel)
+		if d.IsDir() {
+			return nil
+		}
+		return hashEntry(hasher, p, d.Type())
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func hashEntry(w io.Writer, path string, mode fs.FileMode) error {
+	if mode&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, target)
+		return err
+	}
+	if !mode.IsRegular() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// AuditProblem is a place where the audit chain does not hold.
+type AuditProblem struct {
+	File    string
+	Line    int
+	Seq     int64
+	Problem string
+}
+
+// AuditReport is the result of VerifyAuditLog.
+type AuditReport struct {
+	Records   int   // audited records checked
+	FirstSeq  int64 // above 1 when older logs were rotated and pruned
+	LastSeq   int64
+	Unaudited int // records written before audit mode was turned on
+	Problems  []AuditProblem
+}
+
+// VerifyAuditLog walks the audited records of the current and rotated logs
+// in order and reports every record whose hash does not match its content,
+// every break in the sequence numbers or hash links, records without a
+// hash after the chain began, records missing from the start or the end of
+// the logs, and deleted items whose content in the cache no longer matches
+// the record. The logs may start after record 1 only where a rotation ended
+// a log that was pruned since.
+func VerifyAuditLog(config types.Config) (AuditReport, error) {
+	var report AuditReport
+	var prev auditState
+	logDir := ExpandPath(config.Logging.Directory)
+
+	state, err := loadAuditState(logDir)
+	if err != nil {
+		return report, err
+	}
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return report, fmt.Errorf("error loading index: %w", err)
+	}
+	inCache := make(map[string]types.DeletedItem)
+	for _, item := range index.Items {
+		inCache[item.ID] = item
+	}
+
+	for _, path := range LogFiles(config) {
+		file, err := OpenLogFile(path)
+		if err != nil {
+			return report, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := scanner.Text()
+			problem := func(seq int64, format string, args ...any) {
+				report.Problems = append(report.Problems, AuditProblem{
+					File: path, Line: lineNo, Seq: seq, Problem: fmt.Sprintf(format, args...),
+				})
+			}
+
+			var entry types.LogEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Hash == "" {
+				if report.Records == 0 {
+					report.Unaudited++
+				} else {
+					problem(0, "record without a hash inside the chain")
+				}
+				continue
+			}
+
+			m := auditHashSuffix.FindStringSubmatchIndex(line)
+			if m == nil || line[m[2]:m[3]] != entry.Hash {
+				problem(entry.Seq, "hash is not the last field, the record was rewritten")
+			} else if auditHash([]byte(line[:m[0]]+"}")) != entry.Hash {
+				problem(entry.Seq, "record was modified, its hash does not match")
+			}
+
+			if report.Records == 0 {
+				report.FirstSeq = entry.Seq
+				if entry.Seq == 1 && entry.PrevHash != "" {
+					problem(entry.Seq, "first record links to a previous one")
+				} else if entry.Seq > 1 && !state.startsAfterRotation(entry) {
+					problem(entry.Seq, "the logs start at record %d but no rotated log ended at record %d, records were removed from the start", entry.Seq, entry.Seq-1)
+				}
+			} else {
+				if entry.Seq != prev.Seq+1 {
+					problem(entry.Seq, "expected record %d, records are missing or out of order", prev.Seq+1)
+				}
+				if entry.PrevHash != prev.Hash {
+					problem(entry.Seq, "does not link to the hash of record %d", prev.Seq)
+				}
+			}
+
+			if cached, ok := inCache[entry.ItemID]; ok && entry.Operation == "DELETE" && entry.ContentHash != "" && cached.CachePath == entry.CachePath {
+				if hash, err := ContentHash(cached.CachePath); err == nil && hash != entry.ContentHash {
+					problem(entry.Seq, "cached item %s changed since it was deleted", cached.OriginalPath)
+				}
+			}
+
+			report.Records++
+			prev = auditState{Seq: entry.Seq, Hash: entry.Hash}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return report, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	report.LastSeq = prev.Seq
+
+	if state.Seq > prev.Seq || (state.Seq == prev.Seq && state.Hash != prev.Hash) {
+		report.Problems = append(report.Problems, AuditProblem{
+			File:    filepath.Join(logDir, LogFileName),
+			Seq:     state.Seq,
+			Problem: fmt.Sprintf("the log ends at record %d but record %d was written, records were removed from the end", prev.Seq, state.Seq),
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,284 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"vanish/internal/types"
+)
+
+// --- Cache Operations ---
+
+// MoveToCache moves a file, directory, or symlink into the cache, records it
+// in the index and logs the operation. Protected paths are refused. Tags and
+// note from opts are attached to the resulting item. If the move succeeds but
+// the index update fails, the returned item is still populated alongside the
+// error. Children of a directory excluded by a .vanishignore stay in place
+// unless opts.AllowProtected is set.
+func MoveToCache(filename string, config types.Config, opts types.Options) (types.DeletedItem, error) {
+	item, err := moveToCache(filename, config, opts)
+	if item.ID == "" {
+		// Nothing was moved, log what was asked for
+		absPath, _ := filepath.Abs(filename)
+		LogOperation("DELETE", types.DeletedItem{OriginalPath: absPath, BatchID: opts.BatchID}, err, config)
+		return item, err
+	}
+	LogOperation("DELETE", item, err, config)
+	return item, err
+}
+
+func moveToCache(filename string, config types.Config, opts types.Options) (types.DeletedItem, error) {
+	// Last line of defense, callers are expected to have filtered these out already
+	if protection := CheckProtected(filename, config); protection.IsRefused(opts.AllowProtected) {
+		return types.DeletedItem{}, fmt.Errorf("refusing to remove protected path %s: %s", filename, protection.Reason)
+	}
+
+	// Ensure cache directory exists
+	cacheDir := ExpandPath(config.Cache.Directory)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return types.DeletedItem{}, err
+	}
+
+	// Get file info using Lstat (doesn't follow symlinks)
+	stat, err := os.Lstat(filename)
+	if err != nil {
+		return types.DeletedItem{}, err
+	}
+
+	// Get absolute path
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return types.DeletedItem{}, err
+	}
+
+	// Generate unique ID and cache filename
+	now := time.Now()
+	id, cacheFilename := cacheName(filename, now)
+	cachePath := filepath.Join(cacheDir, cacheFilename)
+
+	// Determine file type
+	isSymlink := stat.Mode()&os.ModeSymlink != 0
+	isDir := stat.IsDir()
+	fileCount := 0
+	size := stat.Size()
+	linkTarget := ""
+	var keptPaths []string
+
+	// Note the working tree before the move, the path may be its root
+	gitRepo := FindGitRoot(filename)
+	gitHead := ""
+	if gitRepo != "" {
+		gitHead = ReadGitHead(gitRepo)
+	}
+
+	trace := newTracer(config)
+	started := time.Now()
+
+	// Handle different file types
+	if isSymlink {
+		linkTarget, err = os.Readlink(filename)
+		if err != nil {
+			return types.DeletedItem{}, fmt.Errorf("failed to read symlink: %v", err)
+		}
+
+		if err := moveSymlink(filename, cachePath, trace); err != nil {
+			return types.DeletedItem{}, fmt.Errorf("failed to move symlink: %v", err)
+		}
+	} else if isDir {
+		if !opts.AllowProtected {
+			keptPaths = FindKeptChildren(filename)
+		}
+
+		if len(keptPaths) > 0 {
+			if err := moveDirectoryExcept(filename, cachePath, keptPaths, trace); err != nil {
+				return types.DeletedItem{}, fmt.Errorf("failed to move directory: %v", err)
+			}
+		} else {
+			if err := moveDirectory(filename, cachePath, trace); err != nil {
+				return types.DeletedItem{}, fmt.Errorf("failed to move directory: %v", err)
+			}
+		}
+
+		fileCount, _ = CountFilesInDirectory(cachePath)
+		size, _ = GetDirectorySize(cachePath)
+	} else {
+		if err := moveFile(filename, cachePath, trace); err != nil {
+			return types.DeletedItem{}, fmt.Errorf("failed to move file: %v", err)
+		}
+	}
+	trace.since("FS", started, nil, "moved %s to %s", absPath, cachePath)
+
+	// Create deleted item with all metadata
+	item := types.DeletedItem{
+		ID:           id,
+		OriginalPath: absPath,
+		DeleteDate:   now,
+		CachePath:    cachePath,
+		IsDirectory:  isDir,
+		IsSymlink:    isSymlink,
+		LinkTarget:   linkTarget,
+		FileCount:    fileCount,
+		Size:         size,
+		Tags:         NormalizeTags(opts.Tags),
+		Note:         opts.Note,
+		BatchID:      opts.BatchID,
+		KeptPaths:    keptPaths,
+		GitRepo:      gitRepo,
+		GitHead:      gitHead,
+	}
+
+	// Update index
+	if err := AddToIndex(item, config); err != nil {
+		return item, fmt.Errorf("failed to update index: %v", err)
+	}
+
+	return item, nil
+}
+
+// cacheName returns the item ID and the file name used inside the cache for
+// an item deleted at the given time.
+func cacheName(filename string, now time.Time) (string, string) {
+	id := fmt.Sprintf("%d", now.UnixNano())
+	timestamp := now.Format("2006-01-02-15-04-05")
+	return id, fmt.Sprintf("%s-%s-%s", id, timestamp, filepath.Base(filename))
+}
+
+// NewBatchID returns an ID grouping the items of one batch delete, so they
+// can be restored together with vx restore <batch id>.
+func NewBatchID() string {
+	return fmt.Sprintf("batch-%d", time.Now().UnixNano())
+}
+
+// FindRestoreItems returns the cached items matching any of the patterns
+// and carrying all of the tags. OriginalPath is replaced by a free path when
+// the original location is taken, see resolvePathConflict.
+func FindRestoreItems(patterns, tags []string, config types.Config) ([]types.DeletedItem, error) {
+	index, err := LoadIndex(config)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index: %w", err)
+	}
+
+	var matchingItems []types.DeletedItem
+	for _, item := range FilterItems(index.Items, patterns, tags) {
+		item.OriginalPath = restoreTarget(item)
+		matchingItems = append(matchingItems, item)
+	}
+	return matchingItems, nil
+}
+
+// restoreTarget returns where item would be restored to. A directory that
+// left kept children behind is merged back into its original location,
+// anything else goes to a free path, see resolvePathConflict.
+func restoreTarget(item types.DeletedItem) string {
+	if len(item.KeptPaths) > 0 {
+		if stat, err := os.Lstat(item.OriginalPath); err == nil && stat.IsDir() {
+			return item.OriginalPath
+		}
+	}
+	return resolvePathConflict(item.OriginalPath)
+}
+
+// RestoreFromCache moves a cached item back to its OriginalPath, removes it
+// from the index and logs the operation. The destination must not exist,
+// unless the item is a directory whose kept children are still there; then
+// the cached contents are merged back around them.
+func RestoreFromCache(item types.DeletedItem, config types.Config) error {
+	err := restoreFromCache(item, config)
+	LogOperation("RESTORE", item, err, config)
+	return err
+}
+
+func restoreFromCache(item types.DeletedItem, config types.Config) error {
+	// Check if cache file exists
+	if _, err := os.Lstat(item.CachePath); os.IsNotExist(err) {
+		return fmt.Errorf("cached file not found: %s", item.CachePath)
+	}
+
+	// Create directory for original path if needed
+	originalDir := filepath.Dir(item.OriginalPath)
+	if err := os.MkdirAll(originalDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %v", originalDir, err)
+	}
+
+	trace := newTracer(config)
+	started := time.Now()
+
+	// Restore based on item type. An existing destination is only accepted
+	// for a directory whose kept children stayed behind
+	var err error
+	if stat, statErr := os.Lstat(item.OriginalPath); !os.IsNotExist(statErr) {
+		if len(item.KeptPaths) == 0 || statErr != nil || !stat.IsDir() {
+			return fmt.Errorf("destination already exists: %s", item.OriginalPath)
+		}
+		trace.printf("FS", "merge %s into existing %s", item.CachePath, item.OriginalPath)
+		err = mergeDirectory(item.CachePath, item.OriginalPath, trace)
+	} else if item.IsSymlink {
+		err = restoreSymlink(item.CachePath, item.OriginalPath, trace)
+	} else if item.IsDirectory {
+		err = moveDirectory(item.CachePath, item.OriginalPath, trace)
+	} else {
+		err = moveFile(item.CachePath, item.OriginalPath, trace)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %v", item.ItemType(), err)
+	}
+	trace.since("FS", started, nil, "moved %s to %s", item.CachePath, item.OriginalPath)
+
+	// The item is back in place, so a stale index entry is only logged
+	if err := RemoveFromIndex(item.ID, config); err != nil {
+		LogOperation("INDEX", item, fmt.Errorf("failed to remove restored item from index: %v", err), config)
+	}
+
+	return nil
+}
+
+// CleanupExpired removes every unpinned item older than the configured
+// retention period from the cache and the index. It returns how many items
+// were removed.
+func CleanupExpired(config types.Config) (int, error) {
+	cutoff := time.Now().Add(-time.Duration(config.Cache.Days) * 24 * time.Hour)
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load index for cleanup: %v", err)
+	}
+
+	var remainingItems []types.DeletedItem
+	cleanedCount := 0
+	var freed int64
+
+	for _, item := range index.Items {
+		if !item.IsExpired(cutoff) {
+			remainingItems = append(remainingItems, item)
+			continue
+		}
+		if item.IsDirectory {
+			os.RemoveAll(item.CachePath)
+		} else {
+			os.Remove(item.CachePath)
+		}
+		cleanedCount++
+		freed += item.Size
+
+		LogOperation("CLEANUP", item, nil, config)
+	}
+
+	if cleanedCount == 0 {
+		return 0, nil
+	}
+
+	index.Items = remainingItems
+	if err := SaveIndex(index, config); err != nil {
+		return cleanedCount, fmt.Errorf("failed to update index: %v", err)
+	}
+
+	SendNotification("Vanish cleanup",
+		fmt.Sprintf("Removed %d item(s) older than %d days, freed %s", cleanedCount, config.Cache.Days, FormatBytes(freed)),
+		false, config)
+	return cleanedCount, nil
+}
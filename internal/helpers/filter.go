@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Entry Filters ---
+
+// EntryFilter selects walked entries by age, size and type, see FindMatches.
+// Zero fields are not checked.
+type EntryFilter struct {
+	OlderThan   time.Duration
+	NewerThan   time.Duration
+	LargerThan  int64  // bytes, directories count with their total size
+	SmallerThan int64  // bytes
+	Type        string // "f" regular file, "d" directory, "l" symlink
+	UseAtime    bool   // age from the access time instead of the modification time
+}
+
+// IsZero reports whether the filter selects everything.
+func (f EntryFilter) IsZero() bool {
+	return f == EntryFilter{}
+}
+
+// Matches reports whether the entry at path passes every set criterion.
+// info must come from Lstat so symlinks are judged by themselves.
+func (f EntryFilter) Matches(path string, info fs.FileInfo, now time.Time) bool {
+	switch f.Type {
+	case "f":
+		if !info.Mode().IsRegular() {
+			return false
+		}
+	case "d":
+		if !info.IsDir() {
+			return false
+		}
+	case "l":
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return false
+		}
+	}
+
+	if f.OlderThan > 0 || f.NewerThan > 0 {
+		stamp := info.ModTime()
+		if f.UseAtime {
+			stamp = accessTime(info)
+		}
+		age := now.Sub(stamp)
+		if f.OlderThan > 0 && age <= f.OlderThan {
+			return false
+		}
+		if f.NewerThan > 0 && age >= f.NewerThan {
+			return false
+		}
+	}
+
+	if f.LargerThan > 0 || f.SmallerThan > 0 {
+		size := info.Size()
+		if info.IsDir() {
+			size, _ = GetDirectorySize(path)
+		}
+		if f.LargerThan > 0 && size <= f.LargerThan {
+			return false
+		}
+		if f.SmallerThan > 0 && size >= f.SmallerThan {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseAge parses an age like 30d, 2w, 12h or any time.ParseDuration value.
+func ParseAge(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+
+	switch {
+	case strings.HasSuffix(s, "d"), strings.HasSuffix(s, "w"):
+		unit := 24 * time.Hour
+		if strings.HasSuffix(s, "w") {
+			unit *= 7
+		}
+		var n float64
+		n, err = strconv.ParseFloat(s[:len(s)-1], 64)
+		d = time.Duration(n * float64(unit))
+	default:
+		d, err = time.ParseDuration(s)
+	}
+
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age '%s', use e.g. 30d, 2w or 12h", s)
+	}
+	return d, nil
+}
+
+var sizePattern = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([kmgt]?)(?:i?b)?$`)
+
+// ParseSize parses a size like 100MB, 1.5G or 512k into bytes. Units are
+// powers of 1024, matching FormatBytes.
+func ParseSize(s string) (int64, error) {
+	m := sizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size '%s', use e.g. 100MB, 1.5G or 512k", s)
+	}
+	n, _ := strconv.ParseFloat(m[1], 64)
+	shift := strings.Index("kmgt", strings.ToLower(m[2])) + 1
+	if m[2] == "" {
+		shift = 0
+	}
+	return int64(n * float64(int64(1)<<(10*shift))), nil
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"vanish/internal/types"
+)
+
+// --- Git Awareness ---
+
+// FindGitRoot returns the root of the git working tree containing path, or
+// "" if there is none. Only the filesystem is consulted.
+func FindGitRoot(path string) string {
+	dir := resolveParent(path)
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// gitDirs returns the git directory of the working tree at root and the
+// common directory holding refs, which differ for linked worktrees.
+func gitDirs(root string) (gitDir, commonDir string) {
+	gitDir = filepath.Join(root, ".git")
+	if data, err := os.ReadFile(gitDir); err == nil {
+		// A .git file points elsewhere (worktrees, submodules)
+		target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(root, target)
+		}
+		gitDir = target
+	}
+
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	return gitDir, commonDir
+}
+
+// ReadGitHead returns the commit HEAD points to in the working tree at root,
+// read from .git directly. It is "" for a branch without commits.
+func ReadGitHead(root string) string {
+	gitDir, commonDir := gitDirs(root)
+
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	ref, isRef := strings.CutPrefix(head, "ref: ")
+	if !isRef {
+		return head // detached
+	}
+
+	for _, dir := range []string{gitDir, commonDir} {
+		if data, err := os.ReadFile(filepath.Join(dir, ref)); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+
+	packed, err := os.Open(filepath.Join(commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer packed.Close()
+	scanner := bufio.NewScanner(packed)
+	for scanner.Scan() {
+		if sha, name, ok := strings.Cut(scanner.Text(), " "); ok && name == ref {
+			return sha
+		}
+	}
+	return ""
+}
+
+// CheckGit reports the git working tree path belongs to and the files below
+// path that git has not recorded yet: modified, staged or untracked and not
+// ignored. It returns nil outside a working tree. The changes come from a
+// local git status, so the git binary is required; when it is not
+// installed, Error says so. The repository's fsmonitor and untracked cache
+// are turned off, so git status does not start an fsmonitor program.
+func CheckGit(path string) *types.GitStatus {
+	root := FindGitRoot(path)
+	if root == "" {
+		return nil
+	}
+	status := &types.GitStatus{Root: root, Head: ReadGitHead(root)}
+
+	rel, err := filepath.Rel(root, resolveParent(path))
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	cmd := exec.Command("git", "--no-optional-locks", "-C", root,
+		"-c", "core.fsmonitor=false", "-c", "core.untrackedCache=false",
+		"status", "--porcelain=v1", "-z", "--untracked-files=all", "--no-renames", "--", rel)
+	out, err := cmd.Output()
+	if err != nil {
+		if execErr, ok := err.(*exec.Error); ok {
+			status.Error = fmt.Sprintf("changes not checked: %v", execErr.Err)
+		} else {
+			status.Error = fmt.Sprintf("changes not checked: git status failed: %v", err)
+		}
+		return status
+	}
+	status.Changes = parseGitStatus(out)
+	return status
+}
+
+// parseGitStatus parses the output of git status --porcelain=v1 -z.
+func parseGitStatus(out []byte) []types.GitChange {
+	var changes []types.GitChange
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, path := entry[0], entry[1], string(entry[3:])
+
+		var states []string
+		switch {
+		case x == '?' && y == '?':
+			states = append(states, "untracked")
+		case x == '!':
+			continue
+		default:
+			if x != ' ' {
+				states = append(states, "staged")
+			}
+			if y != ' ' {
+				states = append(states, "modified")
+			}
+		}
+		changes = append(changes, types.GitChange{Path: path, State: strings.Join(states, ", ")})
+	}
+	return changes
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// MatchGlob reports whether a slash-separated path matches pattern. Each
+// segment is matched with filepath.Match, and a "**" segment matches zero or
+// more whole segments, so "**/.git" matches ".git" at any depth.
+func MatchGlob(pattern, path string) bool {
+	pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	return matchSegments(splitSegments(pattern), splitSegments(path))
+}
+
+func splitSegments(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "/")
+}
+
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive ** and try every possible split point
+			rest := pattern[1:]
+			for len(rest) > 0 && rest[0] == "**" {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(rest, path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+		if ok, err := filepath.Match(pattern[0], path[0]); err != nil || !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
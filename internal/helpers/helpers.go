@@ -24,13 +24,53 @@ import (
 	"vanish/internal/types"
 )
 
-// GetConfigPath returns path to vanish.toml
-func GetConfigPath() string {
+// ConfigEnv names the environment variable pointing vx at another config
+// file than vanish.toml.
+const ConfigEnv = "VANISH_CONFIG"
+
+// XDGDir returns the vanish directory in the XDG base directory named by
+// env, e.g. $XDG_DATA_HOME/vanish, or in fallback under homeDir when the
+// variable is unset or not absolute, as the spec asks.
+func XDGDir(homeDir, env, fallback string) string {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, "vanish")
+	}
+	return filepath.Join(homeDir, fallback, "vanish")
+}
+
+// GetConfigDir returns the directory holding vanish.toml and the global
+// ignore file, $XDG_CONFIG_HOME/vanish. ~/.config/vanish is kept while it
+// exists and the XDG one does not, as earlier versions ignored the
+// variable.
+func GetConfigDir() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "could find Config File"
+		return "could find Config Directory"
+	}
+	dir := XDGDir(homeDir, "XDG_CONFIG_HOME", ".config")
+	legacy := filepath.Join(homeDir, ".config", "vanish")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return dir
+}
+
+// GetConfigPath returns path to vanish.toml, or to the file $VANISH_CONFIG
+// names
+func GetConfigPath() string {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		// Relative to the working directory, unlike paths in the file
+		if strings.HasPrefix(path, "~/") {
+			return ExpandPath(path)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+		return path
 	}
-	return filepath.Join(homeDir, ".config", "vanish", "vanish.toml")
+	return filepath.Join(GetConfigDir(), "vanish.toml")
 }
 
 // FormatBytes formats bytes and is used in
@@ -48,34 +88,6 @@ func FormatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// SendNotification sends a desktop notification based on the provided title and message.
-// It only sends notifications if the corresponding flags are enabled in the config.
-// It's tested on only Linux tho it should also work on macOS, and Windows platforms.
-// func SendNotification(title, message string, config types.Config) {
-// 	if !config.Notifications.NotifySuccess && !config.Notifications.NotifyErrors {
-// 		return
-// 	}
-
-// 	if config.Notifications.DesktopEnabled {
-// 		// Run the notification in a separate goroutine to avoid blocking the UI.
-// 		go func() {
-// 			var err error
-
-// 			switch runtime.GOOS {
-// 			case "linux":
-// 				err = exec.Command("notify-send", title, message).Run()
-// 			case "darwin":
-// 				script := fmt.Sprintf(`display notification "%s" with title "%s"`, message, title)
-// 				err = exec.Command("osascript", "-e", script).Run()
-// 			}
-
-// 			if err != nil {
-// 				log.Printf("failed to send notification: %v", err)
-// 			}
-// 		}()
-// 	}
-// }
-
 // SetUpProgress defines progress bar style
 func SetUpProgress(config types.Config) progress.Model {
 	prog := progress.New()
@@ -257,7 +269,7 @@ func ClearAllCache(config types.Config) tea.Cmd {
 
 		// Log clear operation
 		if config.Logging.Enabled {
-			if err := logClearOperation(config); err != nil {
+			if err := LogEvent("CLEAR_ALL", "Cache cleared", nil, config); err != nil {
 				// Log error but don't fail the entire operation
 				// since the cache was successfully cleared
 				return types.ClearMsg{Err: fmt.Errorf("cache cleared but logging failed: %w", err)}
@@ -269,23 +281,16 @@ func ClearAllCache(config types.Config) tea.Cmd {
 }
 
 // PurgeOldFiles removes cached files and directories that are older than
-// the specified number of days. Updates the index and logs each purge
-// if logging is enabled. Returns a tea.Msg containing the purge results.
+// the specified number of days. Pinned items are always kept. Updates the
+// index and logs each purge if logging is enabled. Returns a tea.Msg
+// containing the purge results.
 func PurgeOldFiles(config types.Config, daysStr string) tea.Cmd {
 	return func() tea.Msg {
-		days, err := strconv.Atoi(daysStr)
+		cutoff, err := PurgeCutoff(daysStr)
 		if err != nil {
-			return types.PurgeMsg{Err: fmt.Errorf("invalid days value: %s", daysStr)}
-		}
-
-		// Validate days is positive
-		if days <= 0 {
-			return types.PurgeMsg{Err: fmt.Errorf("days must be positive, got: %d", days)}
+			return types.PurgeMsg{Err: err}
 		}
 
-		cutoffDays := time.Duration(days) * 24 * time.Hour
-		cutoff := time.Now().Add(-cutoffDays)
-
 		index, err := LoadIndex(config)
 		if err != nil {
 			return types.PurgeMsg{Err: fmt.Errorf("error loading index: %w", err)}
@@ -293,11 +298,12 @@ func PurgeOldFiles(config types.Config, daysStr string) tea.Cmd {
 
 		// Pre-allocate slice with estimated capacity
 		remainingItems := make([]types.DeletedItem, 0, len(index.Items))
+		var purgedItems []types.DeletedItem
 		purgedCount := 0
 		var purgeErrors []error
 
 		for _, item := range index.Items {
-			if item.DeleteDate.Before(cutoff) {
+			if item.IsExpired(cutoff) {
 				// Remove the actual file or directory
 				var removeErr error
 				if item.IsDirectory {
@@ -308,6 +314,7 @@ func PurgeOldFiles(config types.Config, daysStr string) tea.Cmd {
 
 				// Track errors but continue purging other files
 				if removeErr != nil && !os.IsNotExist(removeErr) {
+					LogOperation("PURGE", item, removeErr, config)
 					purgeErrors = append(purgeErrors, fmt.Errorf("failed to remove %s: %w", item.CachePath, removeErr))
 					// Keep item in index if we couldn't remove it
 					remainingItems = append(remainingItems, item)
@@ -315,10 +322,11 @@ func PurgeOldFiles(config types.Config, daysStr string) tea.Cmd {
 				}
 
 				purgedCount++
+				purgedItems = append(purgedItems, item)
 
 				// Log purge
 				if config.Logging.Enabled {
-					if err := LogOperation("PURGE", item, config); err != nil {
+					if err := LogOperation("PURGE", item, nil, config); err != nil {
 						// Log error but don't fail the operation
 						purgeErrors = append(purgeErrors, fmt.Errorf("failed to log purge of %s: %w", item.OriginalPath, err))
 					}
@@ -344,34 +352,58 @@ func PurgeOldFiles(config types.Config, daysStr string) tea.Cmd {
 			finalErr = fmt.Errorf("purge completed with errors: %s", strings.Join(errMsgs, "; "))
 		}
 
-		return types.PurgeMsg{PurgedCount: purgedCount, Err: finalErr}
+		return types.PurgeMsg{PurgedCount: purgedCount, Items: purgedItems, Err: finalErr}
+	}
+}
+
+// PurgeCutoff parses the days argument of a purge into the time before
+// which items are purged.
+func PurgeCutoff(daysStr string) (time.Time, error) {
+	days, err := strconv.Atoi(daysStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid days value: %s", daysStr)
+	}
+
+	// Validate days is positive
+	if days <= 0 {
+		return time.Time{}, fmt.Errorf("days must be positive, got: %d", days)
 	}
+
+	return time.Now().Add(-time.Duration(days) * 24 * time.Hour), nil
+}
+
+// FindPurgeItems returns the items a purge of daysStr days would remove,
+// for the pre_purge hook.
+func FindPurgeItems(daysStr string, config types.Config) ([]types.DeletedItem, error) {
+	cutoff, err := PurgeCutoff(daysStr)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return nil, fmt.Errorf("error loading index: %w", err)
+	}
+
+	var items []types.DeletedItem
+	for _, item := range index.Items {
+		if item.IsExpired(cutoff) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
 }
 
 // CheckRestoreItems searches the index for deleted items that match
-// any of the given patterns (case-insensitive substring match).
-// Returns a tea.Msg containing the matched items with resolved paths.
-func CheckRestoreItems(patterns []string, config types.Config) tea.Cmd {
+// any of the given patterns (see MatchesPattern) and carry all of the
+// given tags. Returns a tea.Msg containing the matched items with resolved paths.
+func CheckRestoreItems(patterns, tags []string, config types.Config) tea.Cmd {
 	return func() tea.Msg {
-		index, err := LoadIndex(config)
+		items, err := FindRestoreItems(patterns, tags, config)
 		if err != nil {
-			return types.ErrorMsg(fmt.Sprintf("Error loading index: %v", err))
-		}
-
-		var matchingItems []types.DeletedItem
-		for _, pattern := range patterns {
-			for _, item := range index.Items {
-				// Simple pattern matching - check if pattern is contained in original path
-				if strings.Contains(strings.ToLower(item.OriginalPath), strings.ToLower(pattern)) {
-					// Resolve conflicts for the restore path
-					resolvedItem := item
-					resolvedItem.OriginalPath = resolvePathConflict(item.OriginalPath)
-					matchingItems = append(matchingItems, resolvedItem)
-				}
-			}
+			return types.ErrorMsg(fmt.Sprintf("Error finding items to restore: %v", err))
 		}
-
-		return types.RestoreItemsMsg{Items: matchingItems}
+		return types.RestoreItemsMsg{Items: items}
 	}
 }
 
@@ -408,8 +440,9 @@ func resolvePathConflict(originalPath string) string {
 }
 
 // CheckFilesExist checks if the specified files or directories exist on disk,
-// gathers metadata about each, and returns a tea.Msg with the results.
-func CheckFilesExist(filenames []string) tea.Cmd {
+// gathers metadata about each, marks protected paths (see CheckProtected)
+// and returns a tea.Msg with the results.
+func CheckFilesExist(filenames []string, config types.Config, opts types.Options) tea.Cmd {
 	return func() tea.Msg {
 		fileInfos := make([]types.FileInfo, len(filenames))
 
@@ -424,19 +457,32 @@ func CheckFilesExist(filenames []string) tea.Cmd {
 				continue
 			}
 
-			isDir := stat.IsDir()
-			fileCount := 0
+			info := types.FileInfo{
+				Path:        filename,
+				IsDirectory: stat.IsDir(),
+				Exists:      true,
+				Size:        stat.Size(),
+				ModTime:     stat.ModTime(),
+			}
 
-			if isDir {
-				fileCount, _ = CountFilesInDirectory(filename)
+			if protection := CheckProtected(filename, config); protection != nil {
+				info.ProtectedReason = protection.Reason
+				info.Protected = protection.IsRefused(opts.AllowProtected)
 			}
 
-			fileInfos[i] = types.FileInfo{
-				Path:        filename,
-				IsDirectory: isDir,
-				FileCount:   fileCount,
-				Exists:      true,
+			// Don't walk a refused tree, it may well be the whole filesystem
+			if info.IsDirectory && !info.Protected {
+				info.FileCount, _ = CountFilesInDirectory(filename)
+				info.Size, _ = GetDirectorySize(filename)
+				if !opts.AllowProtected {
+					info.Kept = FindKeptChildren(filename)
+				}
+			}
+			if !info.Protected {
+				info.Git = CheckGit(filename)
 			}
+
+			fileInfos[i] = info
 		}
 
 		return types.FilesExistMsg{FileInfos: fileInfos}
@@ -464,22 +510,23 @@ func CountFilesInDirectory(dir string) (int, error) {
 }
 
 // CountValidFiles returns the number of FileInfo entries that represent
-// existing files or directories.
+// existing, unprotected files or directories.
 func CountValidFiles(fileInfos []types.FileInfo) int {
 	count := 0
 	for _, info := range fileInfos {
-		if info.Exists {
+		if info.Exists && !info.Protected {
 			count++
 		}
 	}
 	return count
 }
 
-// FindNextValidFile returns the index of the next valid file (i.e., one that exists)
-// in the given FileInfo slice, starting from startIndex. Returns -1 if none found.
+// FindNextValidFile returns the index of the next valid file (i.e., one that exists
+// and is not protected) in the given FileInfo slice, starting from startIndex.
+// Returns -1 if none found.
 func FindNextValidFile(fileInfos []types.FileInfo, startIndex int) int {
 	for i := startIndex; i < len(fileInfos); i++ {
-		if fileInfos[i].Exists {
+		if fileInfos[i].Exists && !fileInfos[i].Protected {
 			return i
 		}
 	}
@@ -489,6 +536,10 @@ func FindNextValidFile(fileInfos []types.FileInfo, startIndex int) int {
 // MoveFile moves a file from the source path to the destination path.
 // It handles regular files, symlinks, and special files appropriately.
 func MoveFile(src, dst string) error {
+	return moveFile(src, dst, nil)
+}
+
+func moveFile(src, dst string, trace *tracer) error {
 	// Check if it's a symlink first (before opening)
 	isSymlink, err := IsSymlink(src)
 	if err != nil {
@@ -496,64 +547,59 @@ func MoveFile(src, dst string) error {
 	}
 
 	if isSymlink {
-		return MoveSymlink(src, dst)
+		return moveSymlink(src, dst, trace)
 	}
 
 	// For regular files, use the copy approach
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer sourceFile.Close()
-
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer destFile.Close()
-
-	// Copy file contents
-	_, err = io.Copy(destFile, sourceFile)
-	if err != nil {
-		return err
-	}
-
-	// Get source file permissions
-	srcInfo, err := sourceFile.Stat()
-	if err != nil {
-		return err
-	}
-
-	// Set destination file permissions
-	if err := destFile.Chmod(srcInfo.Mode()); err != nil {
+	if err := copyFile(src, dst, trace); err != nil {
 		return err
 	}
 
 	// Remove original file
-	return os.Remove(src)
+	started := time.Now()
+	err = os.Remove(src)
+	trace.since("FS", started, err, "remove %s", src)
+	return err
 }
 
 // MoveDirectory moves a directory from src to dst. Attempts an atomic move
 // using os.Rename first, and falls back to a copy-and-remove approach
 // if that fails. Properly handles symlinks within directories.
 func MoveDirectory(src, dst string) error {
+	return moveDirectory(src, dst, nil)
+}
+
+func moveDirectory(src, dst string, trace *tracer) error {
 	// Use os.Rename for atomic operation when possible (same filesystem)
-	if err := os.Rename(src, dst); err == nil {
+	started := time.Now()
+	err := os.Rename(src, dst)
+	trace.since("FS", started, err, "rename %s -> %s", src, dst)
+	if err == nil {
 		return nil
 	}
 
 	// Fallback to copy + remove for cross-filesystem moves
-	if err := CopyDirectory(src, dst); err != nil {
+	started = time.Now()
+	err = copyDirectory(src, dst, trace)
+	trace.since("FS", started, err, "copy directory %s -> %s after failed rename", src, dst)
+	if err != nil {
 		return err
 	}
 
-	return os.RemoveAll(src)
+	started = time.Now()
+	err = os.RemoveAll(src)
+	trace.since("FS", started, err, "remove directory %s", src)
+	return err
 }
 
 // CopyDirectory recursively copies the contents of the source directory to the
 // destination directory. Preserves file and directory modes. Returns an error
 // if any operation fails.
 func CopyDirectory(src, dst string) error {
+	return copyDirectory(src, dst, nil)
+}
+
+func copyDirectory(src, dst string, trace *tracer) error {
 	// Use Lstat to not follow symlinks when checking source
 	srcInfo, err := os.Lstat(src)
 	if err != nil {
@@ -561,7 +607,9 @@ func CopyDirectory(src, dst string) error {
 	}
 
 	// Create destination directory
-	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+	err = os.MkdirAll(dst, srcInfo.Mode())
+	trace.since("FS", time.Time{}, err, "mkdir %s", dst)
+	if err != nil {
 		return err
 	}
 
@@ -586,17 +634,19 @@ func CopyDirectory(src, dst string) error {
 			if err != nil {
 				return fmt.Errorf("failed to read symlink %s: %w", srcPath, err)
 			}
-			if err := os.Symlink(linkTarget, dstPath); err != nil {
+			err = os.Symlink(linkTarget, dstPath)
+			trace.since("FS", time.Time{}, err, "symlink %s -> %s", dstPath, linkTarget)
+			if err != nil {
 				return fmt.Errorf("failed to create symlink %s: %w", dstPath, err)
 			}
 		} else if entry.IsDir() {
 			// Handle directory
-			if err := CopyDirectory(srcPath, dstPath); err != nil {
+			if err := copyDirectory(srcPath, dstPath, trace); err != nil {
 				return err
 			}
 		} else {
 			// Handle regular file
-			if err := CopyFile(srcPath, dstPath); err != nil {
+			if err := copyFile(srcPath, dstPath, trace); err != nil {
 				return err
 			}
 		}
@@ -609,29 +659,41 @@ func CopyDirectory(src, dst string) error {
 // Returns an error if opening, copying, or creating fails.
 // Does not follow symlinks - use MoveSymlink for that.
 func CopyFile(src, dst string) error {
+	return copyFile(src, dst, nil)
+}
+
+func copyFile(src, dst string, trace *tracer) error {
+	started := time.Now()
+	copied, err := copyFileContents(src, dst)
+	trace.since("FS", started, err, "copy %s -> %s (%d bytes)", src, dst, copied)
+	return err
+}
+
+// copyFileContents does the work of CopyFile and returns the number of
+// bytes copied.
+func copyFileContents(src, dst string) (int64, error) {
 	srcFile, err := os.Open(src)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer srcFile.Close()
 
 	srcInfo, err := srcFile.Stat()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	dstFile, err := os.Create(dst)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer dstFile.Close()
 
 	if err := dstFile.Chmod(srcInfo.Mode()); err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	return io.Copy(dstFile, srcFile)
 }
 
 // GetDirectorySize returns the total size in bytes of all non-directory
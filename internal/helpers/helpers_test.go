@@ -4,10 +4,21 @@
 package helpers
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/godbus/dbus/v5"
 	"vanish/internal/types"
 )
 
@@ -19,6 +30,61 @@ func TestGetConfigPath(t *testing.T) {
 	if !filepath.IsAbs(path) {
 		t.Errorf("Expected absolute path, got: %s", path)
 	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ConfigEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if got, want := GetConfigPath(), filepath.Join(home, ".config", "vanish", "vanish.toml"); got != want {
+		t.Errorf("GetConfigPath() = %s, want %s", got, want)
+	}
+
+	t.Setenv(ConfigEnv, "~/ci.toml")
+	if got, want := GetConfigPath(), filepath.Join(home, "ci.toml"); got != want {
+		t.Errorf("GetConfigPath() with %s=~/ci.toml = %s, want %s", ConfigEnv, got, want)
+	}
+	wd, _ := os.Getwd()
+	t.Setenv(ConfigEnv, "ci.toml")
+	if got, want := GetConfigPath(), filepath.Join(wd, "ci.toml"); got != want {
+		t.Errorf("GetConfigPath() with %s=ci.toml = %s, want %s", ConfigEnv, got, want)
+	}
+	if got, want := GlobalIgnorePath(), filepath.Join(home, ".config", "vanish", "ignore"); got != want {
+		t.Errorf("GlobalIgnorePath() = %s, want %s, it must not follow %s", got, want, ConfigEnv)
+	}
+}
+
+func TestXDGDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Setenv("XDG_DATA_HOME", "")
+	if got, want := XDGDir(home, "XDG_DATA_HOME", ".local/share"), filepath.Join(home, ".local", "share", "vanish"); got != want {
+		t.Errorf("XDGDir() unset = %s, want %s", got, want)
+	}
+	t.Setenv("XDG_DATA_HOME", "relative/data") // not absolute, ignored
+	if got, want := XDGDir(home, "XDG_DATA_HOME", ".local/share"), filepath.Join(home, ".local", "share", "vanish"); got != want {
+		t.Errorf("XDGDir() relative = %s, want %s", got, want)
+	}
+	t.Setenv("XDG_DATA_HOME", "/srv/data")
+	if got, want := XDGDir(home, "XDG_DATA_HOME", ".local/share"), "/srv/data/vanish"; got != want {
+		t.Errorf("XDGDir() = %s, want %s", got, want)
+	}
+
+	// ~/.config/vanish is kept until the XDG config directory exists
+	xdgConfig := filepath.Join(home, "xdg-config")
+	t.Setenv("XDG_CONFIG_HOME", xdgConfig)
+	if got, want := GetConfigDir(), filepath.Join(xdgConfig, "vanish"); got != want {
+		t.Errorf("GetConfigDir() = %s, want %s", got, want)
+	}
+	legacy := filepath.Join(home, ".config", "vanish")
+	os.MkdirAll(legacy, 0755)
+	if got := GetConfigDir(); got != legacy {
+		t.Errorf("GetConfigDir() = %s, want the existing %s", got, legacy)
+	}
+	os.MkdirAll(filepath.Join(xdgConfig, "vanish"), 0755)
+	if got, want := GetConfigDir(), filepath.Join(xdgConfig, "vanish"); got != want {
+		t.Errorf("GetConfigDir() = %s, want %s once it exists", got, want)
+	}
 }
 
 func TestFormatBytes(t *testing.T) {
@@ -325,7 +391,10 @@ func TestCheckFilesExist(t *testing.T) {
 
 	nonExistingFile := filepath.Join(tmpDir, "not_exists.txt")
 
-	cmd := CheckFilesExist([]string{existingFile, existingDir, nonExistingFile})
+	config := getTestConfig()
+	config.Cache.Directory = t.TempDir()
+
+	cmd := CheckFilesExist([]string{existingFile, existingDir, nonExistingFile}, config, types.Options{})
 	msg := cmd()
 
 	filesMsg, ok := msg.(types.FilesExistMsg)
@@ -519,7 +588,7 @@ func TestCheckRestoreItems(t *testing.T) {
 	}
 	SaveIndex(index, config)
 
-	cmd := CheckRestoreItems([]string{"document"}, config)
+	cmd := CheckRestoreItems([]string{"document"}, nil, config)
 	msg := cmd()
 
 	restoreMsg, ok := msg.(types.RestoreItemsMsg)
@@ -574,6 +643,630 @@ func TestDeletedItemType(t *testing.T) {
 	}
 }
 
+func TestSetPinned(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = tmpDir
+
+	index := types.Index{
+		Items: []types.DeletedItem{
+			{ID: "1", OriginalPath: "/home/user/keep-me.txt"},
+			{ID: "2", OriginalPath: "/home/user/other.txt"},
+		},
+	}
+	SaveIndex(index, config)
+
+	changed, err := SetPinned([]string{"keep"}, true, config)
+	if err != nil {
+		t.Fatalf("SetPinned failed: %v", err)
+	}
+	if len(changed) != 1 || changed[0].ID != "1" {
+		t.Fatalf("Expected item 1 to be pinned, got %v", changed)
+	}
+
+	// Pinning again is a no-op
+	changed, _ = SetPinned([]string{"keep"}, true, config)
+	if len(changed) != 0 {
+		t.Errorf("Expected no changes when re-pinning, got %d", len(changed))
+	}
+
+	loadedIndex, _ := LoadIndex(config)
+	if CountPinned(loadedIndex) != 1 {
+		t.Errorf("Expected 1 pinned item, got %d", CountPinned(loadedIndex))
+	}
+
+	// Unpin by ID
+	changed, _ = SetPinned([]string{"1"}, false, config)
+	if len(changed) != 1 {
+		t.Errorf("Expected 1 unpinned item, got %d", len(changed))
+	}
+}
+
+func TestPurgeOldFilesSkipsPinned(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = tmpDir
+
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	pinnedFile := filepath.Join(tmpDir, "pinned.txt")
+	os.WriteFile(pinnedFile, []byte("pinned"), 0644)
+
+	index := types.Index{
+		Items: []types.DeletedItem{
+			{ID: "pinned", CachePath: pinnedFile, DeleteDate: oldTime, Pinned: true},
+		},
+	}
+	SaveIndex(index, config)
+
+	msg := PurgeOldFiles(config, "7")()
+	purgeMsg := msg.(types.PurgeMsg)
+	if purgeMsg.PurgedCount != 0 {
+		t.Errorf("Expected pinned item to survive purge, purged %d", purgeMsg.PurgedCount)
+	}
+	if _, err := os.Stat(pinnedFile); err != nil {
+		t.Error("Pinned file should still exist")
+	}
+}
+
+func TestPurgeCutoff(t *testing.T) {
+	for _, days := range []string{"0", "-3", "ten", ""} {
+		if _, err := PurgeCutoff(days); err == nil {
+			t.Errorf("PurgeCutoff(%q): expected an error", days)
+		}
+	}
+
+	cutoff, err := PurgeCutoff("2")
+	if err != nil {
+		t.Fatalf("PurgeCutoff(2) failed: %v", err)
+	}
+	if want := time.Now().Add(-48 * time.Hour); cutoff.Sub(want).Abs() > time.Minute {
+		t.Errorf("PurgeCutoff(2) = %v, want about %v", cutoff, want)
+	}
+}
+
+func TestFilterItems(t *testing.T) {
+	items := []types.DeletedItem{
+		{ID: "1", OriginalPath: "/srv/data/users.csv", Tags: []string{"migration-cleanup"}},
+		{ID: "2", OriginalPath: "/srv/data/orders.csv", Note: "old export from billing"},
+		{ID: "3", OriginalPath: "/home/user/notes.txt", Tags: []string{"Migration-Cleanup", "manual"}},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		tags     []string
+		expected []string
+	}{
+		{"Path substring", []string{"csv"}, nil, []string{"1", "2"}},
+		{"Exact ID", []string{"3"}, nil, []string{"3"}},
+		{"Note text is not a pattern", []string{"billing"}, nil, nil},
+		{"Tag text is not a pattern", []string{"manual"}, nil, nil},
+		{"Path, not note, matches", []string{"notes"}, nil, []string{"3"}},
+		{"Tag only", nil, []string{"migration-cleanup"}, []string{"1", "3"}},
+		{"Pattern and tag", []string{"csv"}, []string{"migration-cleanup"}, []string{"1"}},
+		{"All tags required", nil, []string{"migration-cleanup", "manual"}, []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FilterItems(items, tt.patterns, tt.tags)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Expected %d items, got %d", len(tt.expected), len(result))
+			}
+			for i, item := range result {
+				if item.ID != tt.expected[i] {
+					t.Errorf("Item %d: expected ID %s, got %s", i, tt.expected[i], item.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestAnnotateItems(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = tmpDir
+
+	index := types.Index{
+		Items: []types.DeletedItem{
+			{ID: "1", OriginalPath: "/srv/data/users.csv", Tags: []string{"old", "keep"}},
+		},
+	}
+	SaveIndex(index, config)
+
+	note := "  superseded by v2 schema  "
+	changed, err := AnnotateItems([]string{"users"}, []string{"migration", "Old"}, []string{"keep"}, &note, config)
+	if err != nil {
+		t.Fatalf("AnnotateItems failed: %v", err)
+	}
+	if len(changed) != 1 {
+		t.Fatalf("Expected 1 changed item, got %d", len(changed))
+	}
+
+	loadedIndex, _ := LoadIndex(config)
+	item := loadedIndex.Items[0]
+	if len(item.Tags) != 2 || item.Tags[0] != "old" || item.Tags[1] != "migration" {
+		t.Errorf("Unexpected tags: %v", item.Tags)
+	}
+	if item.Note != "superseded by v2 schema" {
+		t.Errorf("Unexpected note: %q", item.Note)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*.txt", "notes.txt", true},
+		{"*.txt", "dir/notes.txt", false},
+		{"**/.git", ".git", true},
+		{"**/.git", "/home/user/project/.git", true},
+		{"/home/*/project", "/home/user/project", true},
+		{"/home/*/project", "/home/user/other/project", false},
+		{"/srv/**/cache/*", "/srv/a/b/cache/x", true},
+		{"/srv/**", "/srv", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if result := MatchGlob(tt.pattern, tt.path); result != tt.expected {
+				t.Errorf("MatchGlob(%q, %q) = %v; expected %v", tt.pattern, tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckProtected(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tmpDir, "home"))
+
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "var", "cache", "vanish")
+	config.Logging.Directory = filepath.Join(tmpDir, "logs")
+	config.Safety.Protected = []string{".git", "~/keep/*"}
+
+	project := filepath.Join(tmpDir, "project")
+	os.MkdirAll(filepath.Join(project, ".git"), 0755)
+	os.MkdirAll(config.Cache.Directory, 0755)
+
+	tests := []struct {
+		name      string
+		path      string
+		protected bool
+		hard      bool
+	}{
+		{"Root", "/", true, true},
+		{"Home", filepath.Join(tmpDir, "home"), true, false},
+		{"Cache ancestor", filepath.Join(tmpDir, "var"), true, true},
+		{"Inside cache", filepath.Join(config.Cache.Directory, "item"), true, true},
+		{"Log directory", config.Logging.Directory, true, false},
+		{"Git directory", filepath.Join(project, ".git"), true, false},
+		{"Home glob", filepath.Join(tmpDir, "home", "keep", "file"), true, false},
+		{"Regular file", filepath.Join(project, "main.go"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protection := CheckProtected(tt.path, config)
+			if (protection != nil) != tt.protected {
+				t.Fatalf("CheckProtected(%s) = %v; expected protected=%v", tt.path, protection, tt.protected)
+			}
+			if protection == nil {
+				return
+			}
+			if protection.Hard != tt.hard {
+				t.Errorf("Expected hard=%v, got %v (%s)", tt.hard, protection.Hard, protection.Reason)
+			}
+			// Soft protections are lifted by the override, hard ones never are
+			if protection.IsRefused(true) != tt.hard {
+				t.Errorf("IsRefused(true) = %v; expected %v", protection.IsRefused(true), tt.hard)
+			}
+		})
+	}
+}
+
+func TestPlanOperations(t *testing.T) {
+	tmpDir := t.TempDir()
+	cacheDir := filepath.Join(tmpDir, "cache")
+	os.MkdirAll(cacheDir, 0755)
+
+	config := getTestConfig()
+	config.Cache.Directory = cacheDir
+	config.Cache.Days = 7
+
+	workFile := filepath.Join(tmpDir, "work.txt")
+	os.WriteFile(workFile, []byte("hello"), 0644)
+
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	oldCached := filepath.Join(cacheDir, "old.txt")
+	os.WriteFile(oldCached, []byte("old"), 0644)
+	index := types.Index{
+		Items: []types.DeletedItem{
+			{ID: "old", OriginalPath: workFile, CachePath: oldCached, DeleteDate: oldTime, Size: 3},
+			{ID: "pinned", OriginalPath: "/tmp/pinned", CachePath: filepath.Join(cacheDir, "gone"), DeleteDate: oldTime, Pinned: true},
+		},
+	}
+	SaveIndex(index, config)
+
+	t.Run("Delete", func(t *testing.T) {
+		plan, err := PlanDelete([]string{workFile, filepath.Join(tmpDir, "missing"), cacheDir}, config, types.Options{})
+		if err != nil {
+			t.Fatalf("PlanDelete failed: %v", err)
+		}
+		if len(plan.Actions) != 3 {
+			t.Fatalf("Expected 3 actions, got %d", len(plan.Actions))
+		}
+		if a := plan.Actions[0]; a.Action != "move" || a.Size != 5 || !strings.HasPrefix(a.Target, cacheDir) {
+			t.Errorf("Unexpected move action: %+v", a)
+		}
+		if plan.Actions[1].Action != "skip" || plan.Actions[2].Action != "refuse" {
+			t.Errorf("Expected skip and refuse, got %s and %s", plan.Actions[1].Action, plan.Actions[2].Action)
+		}
+		if plan.TotalBytes != 5 {
+			t.Errorf("Expected 5 total bytes, got %d", plan.TotalBytes)
+		}
+		if len(plan.Expiring) != 2 || plan.Expiring[0].Action != "cleanup" || plan.Expiring[1].Action != "skip" {
+			t.Errorf("Unexpected expiring actions: %+v", plan.Expiring)
+		}
+		if _, err := os.Stat(workFile); err != nil {
+			t.Error("Dry run must not move the file")
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		plan, err := PlanRestore(nil, nil, config)
+		if err != nil {
+			t.Fatalf("PlanRestore failed: %v", err)
+		}
+		if a := plan.Actions[0]; a.Action != "restore" || !a.Conflict || a.Target == workFile {
+			t.Errorf("Expected conflicting restore, got %+v", a)
+		}
+		if a := plan.Actions[1]; a.Action != "skip" {
+			t.Errorf("Expected missing cache file to be skipped, got %+v", a)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		plan, err := PlanPurge("7", config)
+		if err != nil {
+			t.Fatalf("PlanPurge failed: %v", err)
+		}
+		if len(plan.Actions) != 2 || plan.Actions[0].Action != "purge" || plan.Actions[1].Action != "skip" {
+			t.Errorf("Unexpected purge actions: %+v", plan.Actions)
+		}
+		if plan.TotalBytes != 3 {
+			t.Errorf("Expected 3 total bytes, got %d", plan.TotalBytes)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		plan, err := PlanClear(config)
+		if err != nil {
+			t.Fatalf("PlanClear failed: %v", err)
+		}
+		if len(plan.Actions) != 2 {
+			t.Errorf("Expected 2 actions, got %d", len(plan.Actions))
+		}
+		if _, err := os.Stat(oldCached); err != nil {
+			t.Error("Dry run must not touch the cache")
+		}
+	})
+}
+
+func TestCleanupExpired(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = tmpDir
+	config.Cache.Days = 7
+
+	oldTime := time.Now().Add(-10 * 24 * time.Hour)
+	oldFile := filepath.Join(tmpDir, "old.txt")
+	pinnedFile := filepath.Join(tmpDir, "pinned.txt")
+	newFile := filepath.Join(tmpDir, "new.txt")
+	for _, f := range []string{oldFile, pinnedFile, newFile} {
+		os.WriteFile(f, []byte("data"), 0644)
+	}
+
+	index := types.Index{
+		Items: []types.DeletedItem{
+			{ID: "old", CachePath: oldFile, DeleteDate: oldTime},
+			{ID: "pinned", CachePath: pinnedFile, DeleteDate: oldTime, Pinned: true},
+			{ID: "new", CachePath: newFile, DeleteDate: time.Now()},
+		},
+	}
+	SaveIndex(index, config)
+
+	cleaned, err := CleanupExpired(config)
+	if err != nil {
+		t.Fatalf("CleanupExpired failed: %v", err)
+	}
+	if cleaned != 1 {
+		t.Errorf("Expected 1 item cleaned, got %d", cleaned)
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Error("Expired file should be removed")
+	}
+
+	loaded, _ := LoadIndex(config)
+	if len(loaded.Items) != 2 {
+		t.Errorf("Expected 2 items left in index, got %d", len(loaded.Items))
+	}
+}
+
+func TestReadPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		nul   bool
+		want  []string
+	}{
+		{"lines", "a.txt\nb c.txt\n", false, []string{"a.txt", "b c.txt"}},
+		{"crlf and blank lines", "a.txt\r\n\nb.txt", false, []string{"a.txt", "b.txt"}},
+		{"nul", "a\nb.txt\x00c.txt\x00", true, []string{"a\nb.txt", "c.txt"}},
+		{"nul without trailing separator", "a.txt\x00\x00b.txt", true, []string{"a.txt", "b.txt"}},
+		{"empty", "", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPaths(strings.NewReader(tt.input), tt.nul)
+			if err != nil {
+				t.Fatalf("ReadPaths failed: %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("ReadPaths(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreFromCache(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "cache")
+
+	original := filepath.Join(tmpDir, "work", "file.txt")
+	os.MkdirAll(filepath.Dir(original), 0755)
+	os.WriteFile(original, []byte("data"), 0644)
+
+	item, err := MoveToCache(original, config, types.Options{})
+	if err != nil {
+		t.Fatalf("MoveToCache failed: %v", err)
+	}
+
+	items, err := FindRestoreItems([]string{"file.txt"}, nil, config)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("Expected 1 item to restore, got %d (%v)", len(items), err)
+	}
+	if err := RestoreFromCache(items[0], config); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Errorf("File should be back at %s", original)
+	}
+	if _, err := os.Lstat(item.CachePath); !os.IsNotExist(err) {
+		t.Error("Cached copy should be gone")
+	}
+	if loaded, _ := LoadIndex(config); len(loaded.Items) != 0 {
+		t.Errorf("Expected empty index, got %d items", len(loaded.Items))
+	}
+
+	// A second restore of the same item must not clobber the file
+	if err := RestoreFromCache(item, config); err == nil {
+		t.Error("Expected error restoring an item that is no longer cached")
+	}
+}
+
+func TestFindMatches(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "cache")
+
+	root := filepath.Join(tmpDir, "proj")
+	for _, f := range []string{"a/x.pyc", "a/__pycache__/y.pyc", "venv/lib/z.pyc", "b/.DS_Store", "b/keep.py"} {
+		path := filepath.Join(root, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("data"), 0644)
+	}
+
+	matches, err := FindMatches([]string{root}, MatchOptions{
+		Match:   []string{"*.pyc", "**/__pycache__", ".DS_Store"},
+		Exclude: []string{"venv/**"},
+	}, config)
+	if err != nil {
+		t.Fatalf("FindMatches failed: %v", err)
+	}
+
+	var got []string
+	for _, match := range matches {
+		rel, _ := filepath.Rel(root, match)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"a/__pycache__", "a/x.pyc", "b/.DS_Store"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("FindMatches = %v, want %v", got, want)
+	}
+
+	// Ignore files apply below their own directory only: a's hides its .pyc,
+	// b's rule for it does not leak into a sibling
+	t.Setenv("HOME", tmpDir)
+	os.WriteFile(filepath.Join(root, "a", IgnoreFileName), []byte("x.pyc\n"), 0644)
+	os.WriteFile(filepath.Join(root, "b", IgnoreFileName), []byte("y.pyc\n"), 0644)
+	matches, _ = FindMatches([]string{root}, MatchOptions{Match: []string{"*.pyc"}, Exclude: []string{"venv/**"}}, config)
+	got = nil
+	for _, match := range matches {
+		rel, _ := filepath.Rel(root, match)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	if want := "a/__pycache__/y.pyc"; strings.Join(got, " ") != want {
+		t.Errorf("FindMatches with ignore files = %v, want %v", got, want)
+	}
+
+	// The cache is never walked into, even when it lies below the root
+	cached := filepath.Join(config.Cache.Directory, "old.pyc")
+	os.MkdirAll(config.Cache.Directory, 0755)
+	os.WriteFile(cached, []byte("data"), 0644)
+	matches, _ = FindMatches([]string{tmpDir}, MatchOptions{Match: []string{"*.pyc"}}, config)
+	for _, match := range matches {
+		if match == cached {
+			t.Error("FindMatches should not collect files inside the cache")
+		}
+	}
+}
+
+func TestParseAgeAndSize(t *testing.T) {
+	ages := map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"2w":    14 * 24 * time.Hour,
+		"12h":   12 * time.Hour,
+		"1h30m": 90 * time.Minute,
+	}
+	for input, want := range ages {
+		if got, err := ParseAge(input); err != nil || got != want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "30", "-2d", "3x"} {
+		if _, err := ParseAge(input); err == nil {
+			t.Errorf("ParseAge(%q) should fail", input)
+		}
+	}
+
+	sizes := map[string]int64{
+		"512":   512,
+		"512k":  512 * 1024,
+		"100MB": 100 * 1024 * 1024,
+		"1.5G":  3 * 512 * 1024 * 1024,
+		"2TiB":  2 << 40,
+	}
+	for input, want := range sizes {
+		if got, err := ParseSize(input); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "MB", "10XB", "-5k"} {
+		if _, err := ParseSize(input); err == nil {
+			t.Errorf("ParseSize(%q) should fail", input)
+		}
+	}
+}
+
+func TestFindMatchesWithFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "cache")
+
+	root := filepath.Join(tmpDir, "downloads")
+	os.MkdirAll(filepath.Join(root, "old-dir"), 0755)
+	os.MkdirAll(filepath.Join(root, "new-dir"), 0755)
+	os.WriteFile(filepath.Join(root, "old-dir", "inner.txt"), []byte("data"), 0644)
+	os.WriteFile(filepath.Join(root, "big.iso"), make([]byte, 4096), 0644)
+	os.WriteFile(filepath.Join(root, "small.txt"), []byte("x"), 0644)
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for _, name := range []string{"old-dir", "big.iso", "small.txt"} {
+		os.Chtimes(filepath.Join(root, name), old, old)
+	}
+
+	tests := []struct {
+		name   string
+		filter EntryFilter
+		want   []string
+	}{
+		{"older than", EntryFilter{OlderThan: 30 * 24 * time.Hour}, []string{"big.iso", "old-dir", "small.txt"}},
+		{"older and larger", EntryFilter{OlderThan: 30 * 24 * time.Hour, LargerThan: 1024}, []string{"big.iso"}},
+		{"newer", EntryFilter{NewerThan: time.Hour}, []string{"new-dir"}},
+		{"directories", EntryFilter{Type: "d"}, []string{"new-dir", "old-dir"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := FindMatches([]string{root}, MatchOptions{Filter: tt.filter}, config)
+			if err != nil {
+				t.Fatalf("FindMatches failed: %v", err)
+			}
+			var got []string
+			for _, match := range matches {
+				got = append(got, filepath.Base(match))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("FindMatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVanishIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir) // no global ignore file
+
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "cache")
+
+	proj := filepath.Join(tmpDir, "proj")
+	for _, f := range []string{".git/HEAD", "keep/a", "keep/b.log", "src/main.go", "src/debug.log", "src/vendor/keep.log"} {
+		path := filepath.Join(proj, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("data"), 0644)
+	}
+	os.WriteFile(filepath.Join(proj, IgnoreFileName), []byte("# never vanish these\n.git/\n/keep/\n*.log\n!debug.log\n"), 0644)
+	os.WriteFile(filepath.Join(proj, "src", "vendor", IgnoreFileName), []byte("!keep.log\n"), 0644)
+
+	tests := map[string]bool{
+		".git":                false,
+		"keep":                false,
+		"keep/a":              false, // inside an ignored directory
+		"src":                 true,
+		"src/main.go":         true,
+		"src/debug.log":       true, // re-included by a negation
+		"src/vendor/keep.log": true, // re-included by a nested ignore file
+	}
+	for rel, allowed := range tests {
+		if reason := CheckIgnored(filepath.Join(proj, rel)); (reason == "") != allowed {
+			t.Errorf("CheckIgnored(%s) = %q, want allowed=%v", rel, reason, allowed)
+		}
+	}
+
+	kept := FindKeptChildren(proj)
+	want := []string{".git", "keep", IgnoreFileName}
+	if strings.Join(kept, " ") != strings.Join(want, " ") {
+		t.Errorf("FindKeptChildren = %v, want %v", kept, want)
+	}
+
+	item, err := MoveToCache(proj, config, types.Options{})
+	if err != nil {
+		t.Fatalf("MoveToCache failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(proj, ".git", "HEAD")); err != nil {
+		t.Error(".git should stay in place")
+	}
+	if _, err := os.Stat(filepath.Join(proj, "src")); !os.IsNotExist(err) {
+		t.Error("src should be moved to the cache")
+	}
+
+	items, _ := FindRestoreItems([]string{item.ID}, nil, config)
+	if len(items) != 1 || items[0].OriginalPath != proj {
+		t.Fatalf("Expected the directory to be merged back into %s, got %+v", proj, items)
+	}
+	if err := RestoreFromCache(items[0], config); err != nil {
+		t.Fatalf("RestoreFromCache failed: %v", err)
+	}
+	for _, f := range []string{".git/HEAD", "keep/a", "src/main.go"} {
+		if _, err := os.Stat(filepath.Join(proj, f)); err != nil {
+			t.Errorf("%s missing after restore", f)
+		}
+	}
+}
+
 // Helper function to create a test config
 func getTestConfig() types.Config {
 	var config types.Config
@@ -602,3 +1295,628 @@ func getTestConfig() types.Config {
 
 	return config
 }
+
+func TestReadGitHead(t *testing.T) {
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git")
+	os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0755)
+	os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+
+	sub := filepath.Join(repo, "src", "pkg")
+	os.MkdirAll(sub, 0755)
+	if root := FindGitRoot(sub); root != repo {
+		t.Fatalf("expected root %s, got %q", repo, root)
+	}
+	if root := FindGitRoot(t.TempDir()); root != "" {
+		t.Errorf("expected no root outside a repository, got %q", root)
+	}
+
+	if head := ReadGitHead(repo); head != "" {
+		t.Errorf("expected no head before the first commit, got %q", head)
+	}
+
+	packed := "# pack-refs with: peeled\n1111111111111111111111111111111111111111 refs/heads/main\n"
+	os.WriteFile(filepath.Join(gitDir, "packed-refs"), []byte(packed), 0644)
+	if head := ReadGitHead(repo); head != strings.Repeat("1", 40) {
+		t.Errorf("expected head from packed-refs, got %q", head)
+	}
+
+	loose := strings.Repeat("2", 40)
+	os.WriteFile(filepath.Join(gitDir, "refs", "heads", "main"), []byte(loose+"\n"), 0644)
+	if head := ReadGitHead(repo); head != loose {
+		t.Errorf("expected loose ref to win, got %q", head)
+	}
+
+	// A linked worktree points to its git dir with a .git file
+	worktree := t.TempDir()
+	wtGitDir := filepath.Join(gitDir, "worktrees", "wt")
+	os.MkdirAll(wtGitDir, 0755)
+	os.WriteFile(filepath.Join(wtGitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+	os.WriteFile(filepath.Join(wtGitDir, "commondir"), []byte("../..\n"), 0644)
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+wtGitDir+"\n"), 0644)
+	if head := ReadGitHead(worktree); head != loose {
+		t.Errorf("expected worktree head from the common dir, got %q", head)
+	}
+}
+
+func TestCheckGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(rel, data string) {
+		path := filepath.Join(repo, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(data), 0644)
+	}
+
+	git("init", "-q")
+	write("src/committed.go", "v1")
+	write("src/staged.go", "v1")
+	write(".gitignore", "*.o\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	write("src/committed.go", "v2")
+	write("src/staged.go", "v2")
+	git("add", "src/staged.go")
+	write("src/new.go", "new")
+	write("src/build.o", "ignored")
+	write("docs/readme", "outside")
+
+	// The repository's config must not get to run a program
+	marker := filepath.Join(t.TempDir(), "ran")
+	write("fsmonitor.sh", "#!/bin/sh\ntouch "+marker+"\n")
+	os.Chmod(filepath.Join(repo, "fsmonitor.sh"), 0755)
+	git("config", "core.fsmonitor", filepath.Join(repo, "fsmonitor.sh"))
+
+	status := CheckGit(filepath.Join(repo, "src"))
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("git status ran the repository's core.fsmonitor")
+	}
+	if status == nil {
+		t.Fatal("expected a git status inside the repository")
+	}
+	if len(status.Head) != 40 {
+		t.Errorf("expected a commit hash as head, got %q", status.Head)
+	}
+	if status.Error != "" {
+		t.Fatalf("unexpected error: %s", status.Error)
+	}
+
+	got := map[string]string{}
+	for _, change := range status.Changes {
+		got[change.Path] = change.State
+	}
+	want := map[string]string{
+		"src/committed.go": "modified",
+		"src/staged.go":    "staged",
+		"src/new.go":       "untracked",
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected changes %v, got %v", want, got)
+	}
+	for path, state := range want {
+		if got[path] != state {
+			t.Errorf("expected %s to be %q, got %q", path, state, got[path])
+		}
+	}
+
+	if status := CheckGit(t.TempDir()); status != nil {
+		t.Errorf("expected no status outside a repository, got %+v", status)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+
+	tmpDir := t.TempDir()
+	config := getTestConfig()
+	config.Logging.Enabled = false
+	config.Hooks.Timeout = 5
+	out := filepath.Join(tmpDir, "out")
+
+	// The payload arrives on stdin and is summarized in the environment
+	config.Hooks.PostRestore = `cat > "` + out + `"; echo "$VANISH_HOOK_EVENT $VANISH_HOOK_COUNT $VANISH_HOOK_IDS" >> "` + out + `"`
+	items := []types.DeletedItem{{ID: "a1", OriginalPath: "/x/a"}, {ID: "b2", OriginalPath: "/x/b"}}
+	if err := RunHook(HookPostRestore, nil, items, config, types.Options{}); err != nil {
+		t.Fatalf("RunHook failed: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.Contains(string(data), `"paths":["/x/a","/x/b"]`) || !strings.HasSuffix(string(data), "post_restore 2 a1 b2\n") {
+		t.Errorf("unexpected hook input: %s", data)
+	}
+
+	// A failing hook reports its stderr, unless hooks are disabled
+	config.Hooks.PreDelete = "echo 'disk is busy' >&2; exit 3"
+	err := RunHook(HookPreDelete, []string{"/x/a"}, nil, config, types.Options{})
+	if err == nil || !strings.Contains(err.Error(), "disk is busy") {
+		t.Errorf("expected the hook's stderr in the error, got %v", err)
+	}
+	if err := RunHook(HookPreDelete, []string{"/x/a"}, nil, config, types.Options{NoHooks: true}); err != nil {
+		t.Errorf("expected --no-hooks to skip the hook, got %v", err)
+	}
+
+	// Unconfigured events do nothing
+	if err := RunHook(HookPostClear, nil, nil, config, types.Options{}); err != nil {
+		t.Errorf("expected no error without a hook, got %v", err)
+	}
+
+	config.Hooks.Timeout = 1
+	config.Hooks.PrePurge = "sleep 10"
+	start := time.Now()
+	err = RunHook(HookPrePurge, nil, nil, config, types.Options{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("hook was not killed on timeout")
+	}
+}
+
+// mockNotifications implements org.freedesktop.Notifications.Notify.
+type mockNotifications struct {
+	received chan []string
+}
+
+func (m *mockNotifications) Notify(app string, _ uint32, icon, summary, body string,
+	_ []string, hints map[string]dbus.Variant, _ int32) (uint32, *dbus.Error) {
+	urgency, _ := hints["urgency"].Value().(byte)
+	m.received <- []string{app, icon, summary, body, strconv.Itoa(int(urgency))}
+	return 1, nil
+}
+
+func TestSendNotification(t *testing.T) {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	// A private session bus with a mock notification service on it
+	address := "unix:path=" + filepath.Join(t.TempDir(), "bus")
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address", "--address="+address)
+	stdout, _ := cmd.StdoutPipe()
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+	// The address is printed once the bus listens
+	if _, err := bufio.NewReader(stdout).ReadString('\n'); err != nil {
+		t.Fatalf("dbus-daemon did not start: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := dbus.Connect(address, dbus.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("failed to connect to the test bus: %v", err)
+	}
+	defer conn.Close()
+	mock := &mockNotifications{received: make(chan []string, 1)}
+	conn.Export(mock, notifyPath, notifyService)
+	if reply, err := conn.RequestName(notifyService, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("failed to own %s: %v", notifyService, err)
+	}
+
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", address)
+	config := getTestConfig()
+	config.Logging.Enabled = false
+	config.Notifications.DesktopEnabled = true
+	config.Notifications.NotifyErrors = true
+
+	if err := SendNotification("Vanish delete failed", "disk full", true, config); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	select {
+	case got := <-mock.received:
+		want := []string{"vanish", "dialog-error", "Vanish delete failed", "disk full", "2"}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("expected notification %v, got %v", want, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("mock service received no notification")
+	}
+
+	// Success messages are off unless notify_success is set
+	config.Notifications.NotifySuccess = false
+	if err := SendNotification("Vanish", "done", false, config); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	select {
+	case got := <-mock.received:
+		t.Errorf("expected no notification with notify_success off, got %v", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Without a service on the bus the error is reported, not hidden
+	conn.ReleaseName(notifyService)
+	if err := SendNotification("Vanish delete failed", "disk full", true, config); err == nil {
+		t.Error("expected an error without a notification service")
+	}
+}
+
+func TestWriteLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := getTestConfig()
+	config.Logging.Enabled = true
+	config.Logging.Directory = filepath.Join(tmpDir, "logs")
+	config.Logging.Format = "json"
+	logPath := filepath.Join(config.Logging.Directory, LogFileName)
+
+	item := types.DeletedItem{ID: "42", BatchID: "batch-1", OriginalPath: "/home/u/a", CachePath: "/cache/a", Size: 10}
+	LogOperation("DELETE", item, nil, config)
+	LogEvent("HOOK", "pre_delete", fmt.Errorf("exit status 1"), config)
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), data)
+	}
+
+	var entry types.LogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if entry.Operation != "DELETE" || entry.ItemID != "42" || entry.BatchID != "batch-1" || entry.Size != 10 ||
+		entry.Outcome != OutcomeOK || entry.PID != os.Getpid() || entry.Time.IsZero() {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if entry.Outcome != OutcomeError || entry.Error != "exit status 1" || entry.Message != "pre_delete" {
+		t.Errorf("unexpected error entry: %+v", entry)
+	}
+
+	// The text format keeps the old line shape
+	config.Logging.Format = "text"
+	LogOperation("RESTORE", item, nil, config)
+	data, _ = os.ReadFile(logPath)
+	if !strings.HasSuffix(string(data), " [FILE] RESTORE: /home/u/a -> /cache/a\n") {
+		t.Errorf("unexpected text line: %s", data)
+	}
+
+	// Disabled logging writes nothing
+	config.Logging.Enabled = false
+	os.Remove(logPath)
+	LogEvent("CLEAR_ALL", "Cache cleared", nil, config)
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("expected no log file with logging disabled")
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "cache")
+	config.Logging.Enabled = true
+	config.Logging.Directory = filepath.Join(tmpDir, "logs")
+	config.Logging.Level = LevelWarn
+	logPath := filepath.Join(config.Logging.Directory, LogFileName)
+
+	readEntries := func() []types.LogEntry {
+		data, _ := os.ReadFile(logPath)
+		var entries []types.LogEntry
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var entry types.LogEntry
+			if line != "" && json.Unmarshal([]byte(line), &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	// At warn, operations are dropped and failures kept
+	LogEvent("CLEAR_ALL", "Cache cleared", nil, config)
+	LogWarning("NOTIFY", "Vanish", fmt.Errorf("no bus"), config)
+	LogEvent("HOOK", "pre_delete", fmt.Errorf("exit status 1"), config)
+	entries := readEntries()
+	if len(entries) != 2 || entries[0].Level != LevelWarn || entries[1].Level != LevelError {
+		t.Fatalf("expected a warn and an error entry, got %+v", entries)
+	}
+
+	// -v lowers the level to info, but never raises it
+	if got := WithVerbosity(config, 1).Logging.Level; got != LevelInfo {
+		t.Errorf("expected -v to give info, got %s", got)
+	}
+	config.Logging.Level = LevelDebug
+	if got := WithVerbosity(config, 1).Logging.Level; got != LevelDebug {
+		t.Errorf("expected -v to keep debug, got %s", got)
+	}
+
+	// Debug traces the filesystem calls and index saves of a delete
+	os.Remove(logPath)
+	src := filepath.Join(tmpDir, "file.txt")
+	os.WriteFile(src, []byte("hello"), 0644)
+	if _, err := MoveToCache(src, config, types.Options{}); err != nil {
+		t.Fatalf("MoveToCache failed: %v", err)
+	}
+	var copied, saved bool
+	for _, entry := range readEntries() {
+		if entry.Level != LevelDebug {
+			continue
+		}
+		if entry.Operation == "FS" && strings.HasPrefix(entry.Message, "copy "+src) && strings.HasSuffix(entry.Message, "(5 bytes)") {
+			copied = true
+		}
+		if entry.Operation == "INDEX" && strings.HasPrefix(entry.Message, "save ") {
+			saved = true
+		}
+	}
+	if !copied || !saved {
+		t.Errorf("expected copy and index save traces, got %+v", readEntries())
+	}
+
+	// Without debug there are no traces
+	config.Logging.Level = LevelInfo
+	os.Remove(logPath)
+	LoadIndex(config)
+	if entries := readEntries(); len(entries) != 0 {
+		t.Errorf("expected no traces at info, got %+v", entries)
+	}
+}
+
+func TestLogRotation(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := getTestConfig()
+	config.Logging.Enabled = true
+	config.Logging.Directory = tmpDir
+	config.Logging.MaxSize = 1
+	config.Logging.MaxFiles = 2
+	config.Logging.Compress = true
+	logPath := filepath.Join(tmpDir, LogFileName)
+
+	// A log at max_size is rotated and compressed before the next entry
+	os.WriteFile(logPath, []byte(strings.Repeat("x", 1024*1024-1)+"\n"), 0644)
+	if err := LogEvent("CLEAR_ALL", "Cache cleared", nil, config); err != nil {
+		t.Fatalf("WriteLog failed: %v", err)
+	}
+	files := LogFiles(config)
+	if len(files) != 2 || !strings.HasSuffix(files[0], ".log.gz") || files[1] != logPath {
+		t.Fatalf("expected a rotated log and vanish.log, got %v", files)
+	}
+	rotated, err := OpenLogFile(files[0])
+	if err != nil {
+		t.Fatalf("OpenLogFile failed: %v", err)
+	}
+	data, _ := io.ReadAll(rotated)
+	rotated.Close()
+	if len(data) != 1024*1024 {
+		t.Errorf("expected the old log in the rotated file, got %d bytes", len(data))
+	}
+	if data, _ := os.ReadFile(logPath); strings.Count(string(data), "\n") != 1 {
+		t.Errorf("expected one entry in the new log, got %q", data)
+	}
+
+	// Only max_files rotated logs are kept, and none older than max_age
+	for _, name := range []string{"vanish-2020-01-01T00-00-00.000.log", "vanish-2021-01-01T00-00-00.000.log"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("old\n"), 0644)
+	}
+	if err := pruneLogs(tmpDir, config); err != nil {
+		t.Fatalf("pruneLogs failed: %v", err)
+	}
+	if got := rotatedLogs(tmpDir); len(got) != 2 || filepath.Base(got[0]) != "vanish-2021-01-01T00-00-00.000.log" {
+		t.Errorf("expected the two newest rotated logs, got %v", got)
+	}
+	config.Logging.MaxAge = 1
+	os.Chtimes(filepath.Join(tmpDir, "vanish-2021-01-01T00-00-00.000.log"), time.Now(), time.Now().Add(-48*time.Hour))
+	pruneLogs(tmpDir, config)
+	if got := rotatedLogs(tmpDir); len(got) != 1 || !strings.HasSuffix(got[0], ".log.gz") {
+		t.Errorf("expected logs older than max_age removed, got %v", got)
+	}
+
+	// max_age also applies without a rotation, on the first write of a process
+	stale := filepath.Join(tmpDir, "vanish-2022-01-01T00-00-00.000.log")
+	os.WriteFile(stale, []byte("old\n"), 0644)
+	os.Chtimes(stale, time.Now(), time.Now().Add(-48*time.Hour))
+	logsPruned.Store(false)
+	LogEvent("CLEAR_ALL", "Cache cleared", nil, config)
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected %s removed by the first write, got %v", stale, err)
+	}
+
+	// Concurrent writers lose no entries across a rotation
+	config.Logging.MaxFiles = 0
+	config.Logging.Compress = false
+	os.WriteFile(logPath, []byte(strings.Repeat("x", 1024*1024-4096)+"\n"), 0644)
+	var wg sync.WaitGroup
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				LogEvent("TEST", strings.Repeat("y", 100), nil, config)
+			}
+		}()
+	}
+	wg.Wait()
+	count := 0
+	for _, path := range LogFiles(config) {
+		if strings.HasSuffix(path, ".gz") {
+			continue
+		}
+		data, _ := os.ReadFile(path)
+		count += strings.Count(string(data), `"op":"TEST"`)
+	}
+	if count != 500 {
+		t.Errorf("expected 500 entries across the logs, got %d", count)
+	}
+}
+
+func TestReadHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := getTestConfig()
+	config.Logging.Enabled = true
+	config.Logging.Directory = tmpDir
+
+	// A gzipped rotated log in the old text format, then the JSON log
+	old := strings.Join([]string{
+		"2026-01-05 10:00:00 [FILE] DELETE: /home/u/notes.txt -> /cache/1-notes.txt",
+		"2026-01-05 10:01:00 [CLEAR_ALL] Cache cleared",
+		"2026-01-05 10:02:00 DEBUG [FS] rename /a -> /b (0.041ms) (error: cross-device link)",
+		"not a log line",
+	}, "\n") + "\n"
+	rotated := filepath.Join(tmpDir, "vanish-2026-01-06T00-00-00.000.log")
+	os.WriteFile(rotated, []byte(old), 0644)
+	if err := compressLog(rotated); err != nil {
+		t.Fatalf("compressLog failed: %v", err)
+	}
+	item := types.DeletedItem{ID: "42", OriginalPath: "/home/u/report.pdf", CachePath: "/cache/42-report.pdf"}
+	LogOperation("DELETE", item, nil, config)
+	LogOperation("RESTORE", item, fmt.Errorf("destination already exists"), config)
+
+	entries, err := ReadHistory(HistoryFilter{}, config)
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries without the debug trace, got %+v", entries)
+	}
+	first := entries[0]
+	if first.Operation != "DELETE" || first.OriginalPath != "/home/u/notes.txt" || first.CachePath != "/cache/1-notes.txt" || first.Time.Day() != 5 {
+		t.Errorf("text line parsed wrong: %+v", first)
+	}
+	if entries[1].Operation != "CLEAR_ALL" || entries[1].Message != "Cache cleared" {
+		t.Errorf("text event parsed wrong: %+v", entries[1])
+	}
+	if last := entries[3]; last.Level != LevelError || last.Error != "destination already exists" {
+		t.Errorf("expected the failed restore last, got %+v", last)
+	}
+
+	since, _ := ParseHistoryTime("2026-01-05 10:00:30", false)
+	until, _ := ParseHistoryTime("2026-01-05", true)
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   int
+	}{
+		{"operation", HistoryFilter{Operations: []string{"DELETE"}}, 2},
+		{"clear means CLEAR_ALL", HistoryFilter{Operations: []string{"CLEAR"}}, 1},
+		{"path", HistoryFilter{Path: "REPORT"}, 2},
+		{"date range", HistoryFilter{Since: since, Until: until}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := ReadHistory(tt.filter, config)
+			if len(got) != tt.want {
+				t.Errorf("expected %d entries, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	// Entries are tied back to items still in the cache
+	index := types.Index{Items: []types.DeletedItem{item, {ID: "1", CachePath: "/cache/1-notes.txt"}}}
+	items := NewLoggedItems(index)
+	if found, ok := items.Find(entries[2]); !ok || found.ID != "42" {
+		t.Errorf("expected item 42 by ID, got %+v", found)
+	}
+	if found, ok := items.Find(first); !ok || found.ID != "1" {
+		t.Errorf("expected item 1 by cache path, got %+v", found)
+	}
+	if found, ok := items.Find(types.LogEntry{ItemID: "7", CachePath: "/cache/1-notes.txt"}); ok {
+		t.Errorf("expected an entry with an unknown ID not to match by cache path, got %+v", found)
+	}
+	index.Items[1].CachePath = "/moved/1-notes.txt"
+	if found, ok := NewLoggedItems(index).Find(first); !ok || found.ID != "1" {
+		t.Errorf("expected item 1 after the store moved, got %+v", found)
+	}
+}
+
+func TestAuditChain(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := getTestConfig()
+	config.Cache.Directory = filepath.Join(tmpDir, "cache")
+	config.Logging.Enabled = true
+	config.Logging.Directory = filepath.Join(tmpDir, "logs")
+	config.Logging.Format = "text" // audit mode writes json regardless
+	config.Logging.Audit = true
+	logPath := filepath.Join(config.Logging.Directory, LogFileName)
+
+	src := filepath.Join(tmpDir, "report.txt")
+	os.WriteFile(src, []byte("quarterly numbers"), 0644)
+	item, err := MoveToCache(src, config, types.Options{})
+	if err != nil {
+		t.Fatalf("MoveToCache failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		LogEvent("CLEAR_ALL", "Cache cleared", nil, config)
+	}
+
+	data, _ := os.ReadFile(logPath)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var first types.LogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("audited record is not JSON: %v", err)
+	}
+	want, _ := ContentHash(item.CachePath)
+	if first.Seq != 1 || first.PrevHash != "" || first.Hash == "" || first.ContentHash != want {
+		t.Errorf("unexpected first record: %+v", first)
+	}
+
+	report, err := VerifyAuditLog(config)
+	if err != nil || len(report.Problems) != 0 || report.Records != 4 {
+		t.Fatalf("expected an intact chain of 4 records, got %+v (%v)", report, err)
+	}
+
+	verify := func(name string, content []string, wantProblem string) {
+		t.Helper()
+		os.WriteFile(logPath, []byte(strings.Join(content, "\n")+"\n"), 0644)
+		report, err := VerifyAuditLog(config)
+		if err != nil {
+			t.Fatalf("%s: VerifyAuditLog failed: %v", name, err)
+		}
+		found := false
+		for _, p := range report.Problems {
+			found = found || strings.Contains(p.Problem, wantProblem)
+		}
+		if !found {
+			t.Errorf("%s: expected a problem containing %q, got %+v", name, wantProblem, report.Problems)
+		}
+	}
+
+	edited := append([]string{}, lines...)
+	edited[1] = strings.Replace(edited[1], "Cache cleared", "Nothing happened", 1)
+	verify("modified", edited, "was modified")
+	verify("removed", []string{lines[0], lines[2], lines[3]}, "missing or out of order")
+	verify("truncated", lines[:3], "removed from the end")
+	verify("cut at the start", lines[1:], "removed from the start")
+
+	// The content of a deleted item is covered too
+	os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	os.WriteFile(item.CachePath, []byte("different numbers"), 0644)
+	verify("content", lines, "changed since it was deleted")
+
+	// A pruned log may be missing from the start, where a rotation ended it
+	os.WriteFile(item.CachePath, []byte("quarterly numbers"), 0644)
+	config.Logging.MaxSize = 1
+	for i := 0; i < 3; i++ {
+		LogEvent("TEST", strings.Repeat("y", 600*1024), nil, config)
+	}
+	LogEvent("CLEAR_ALL", "Cache cleared", nil, config)
+	rotated := rotatedLogs(config.Logging.Directory)
+	if len(rotated) != 1 {
+		t.Fatalf("expected one rotated log, got %v", rotated)
+	}
+	os.Remove(rotated[0])
+	report, err = VerifyAuditLog(config)
+	if err != nil || len(report.Problems) != 0 || report.FirstSeq != 7 {
+		t.Fatalf("expected the chain to start at record 7 after pruning, got %+v (%v)", report, err)
+	}
+	data, _ = os.ReadFile(logPath)
+	lines = strings.Split(strings.TrimSpace(string(data)), "\n")
+	verify("cut after a rotation", lines[1:], "removed from the start")
+}
@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"vanish/internal/types"
+)
+
+// --- Operation History ---
+
+// HistoryOperations are the operations vx history can filter by. CLEAR is
+// short for CLEAR_ALL.
+var HistoryOperations = []string{"DELETE", "RESTORE", "PURGE", "CLEANUP", "CLEAR", "INDEX", "HOOK", "NOTIFY"}
+
+// HistoryFilter selects log entries for vx history. Zero fields match
+// everything. Debug traces are never part of the history.
+type HistoryFilter struct {
+	Operations []string // upper case, see HistoryOperations
+	Since      time.Time
+	Until      time.Time
+	Path       string // case-insensitive substring of the original path
+}
+
+// Matches reports whether entry passes the filter.
+func (f HistoryFilter) Matches(entry types.LogEntry) bool {
+	if entry.Level == LevelDebug {
+		return false
+	}
+	if len(f.Operations) > 0 {
+		found := false
+		for _, op := range f.Operations {
+			if entry.Operation == op || (op == "CLEAR" && entry.Operation == "CLEAR_ALL") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	return f.Path == "" || strings.Contains(strings.ToLower(entry.OriginalPath), strings.ToLower(f.Path))
+}
+
+// ParseHistoryOperation returns the operation name vx history filters by,
+// accepting any case.
+func ParseHistoryOperation(s string) (string, error) {
+	op := strings.ToUpper(s)
+	if op == "CLEAR_ALL" {
+		op = "CLEAR"
+	}
+	for _, known := range HistoryOperations {
+		if op == known {
+			return op, nil
+		}
+	}
+	return "", fmt.Errorf("unknown operation '%s', use one of %s", s, strings.ToLower(strings.Join(HistoryOperations, ", ")))
+}
+
+// historyLayouts are the date formats accepted by --since and --until.
+var historyLayouts = []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// ParseHistoryTime parses a --since or --until value: an age like 7d or
+// 12h counted back from now, or a local date with an optional time. A bare
+// date used as the end of a range (end) includes the whole day.
+func ParseHistoryTime(s string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range historyLayouts {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		if end && layout == "2006-01-02" {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if age, err := ParseAge(s); err == nil {
+		return time.Now().Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s', use a date like 2026-01-31, a time like \"2026-01-31 14:00\" or an age like 7d", s)
+}
+
+// ReadHistory returns the entries of the current and rotated logs that
+// pass filter, oldest first. Lines that are neither JSON entries nor text
+// log lines are skipped.
+func ReadHistory(filter HistoryFilter, config types.Config) ([]types.LogEntry, error) {
+	var entries []types.LogEntry
+	for _, path := range LogFiles(config) {
+		file, err := OpenLogFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			entry, ok := ParseLogLine(scanner.Text())
+			if ok && filter.Matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return entries, nil
+}
+
+// ParseLogLine parses a line of vanish.log in either format. Entries
+// written before log levels existed are info, or error when they failed.
+func ParseLogLine(line string) (types.LogEntry, bool) {
+	var entry types.LogEntry
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Operation == "" {
+			return entry, false
+		}
+	} else {
+		var ok bool
+		if entry, ok = parseTextLog(line); !ok {
+			return entry, false
+		}
+	}
+
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeOK
+	}
+	if entry.Level == "" {
+		entry.Level = LevelInfo
+		if entry.Outcome == OutcomeError {
+			entry.Level = LevelError
+		}
+	}
+	return entry, true
+}
+
+var textDuration = regexp.MustCompile(` \((\d+(?:\.\d+)?)ms\)$`)
+
+// parseTextLog reverses formatTextLog. The text format has no item IDs,
+// users or PIDs, so those stay empty.
+func parseTextLog(line string) (types.LogEntry, bool) {
+	var entry types.LogEntry
+	const stamp = "2006-01-02 15:04:05"
+	if len(line) < len(stamp)+2 {
+		return entry, false
+	}
+	t, err := time.ParseInLocation(stamp, line[:len(stamp)], time.Local)
+	if err != nil {
+		return entry, false
+	}
+	entry.Time = t
+	rest := line[len(stamp)+1:]
+
+	if level, after, ok := strings.Cut(rest, " "); ok && ValidLogLevel(strings.ToLower(level)) {
+		entry.Level = strings.ToLower(level)
+		rest = after
+	}
+
+	if i := strings.LastIndex(rest, " (error: "); i >= 0 && strings.HasSuffix(rest, ")") {
+		entry.Outcome = OutcomeError
+		entry.Error = rest[i+len(" (error: ") : len(rest)-1]
+		rest = rest[:i]
+	}
+	if m := textDuration.FindStringSubmatch(rest); m != nil {
+		entry.DurationMS, _ = strconv.ParseFloat(m[1], 64)
+		rest = rest[:len(rest)-len(m[0])]
+	}
+
+	tag, rest, ok := strings.Cut(strings.TrimPrefix(rest, "["), "] ")
+	if !ok {
+		return entry, false
+	}
+	if tag == "FILE" || tag == "DIR" {
+		op, paths, ok := strings.Cut(rest, ": ")
+		if !ok {
+			return entry, false
+		}
+		entry.Operation = op
+		entry.IsDirectory = tag == "DIR"
+		entry.OriginalPath, entry.CachePath, _ = strings.Cut(paths, " -> ")
+	} else {
+		entry.Operation = tag
+		entry.Message = rest
+	}
+	return entry, true
+}
+
+// LoggedItems looks up the cached items log entries are about, see
+// NewLoggedItems.
+type LoggedItems struct {
+	byID   map[string]types.DeletedItem
+	byName map[string]types.DeletedItem // by the name of the cache path
+}
+
+// NewLoggedItems indexes the items of index by ID and by the name of their
+// cache path, once for all the entries of a log.
+func NewLoggedItems(index types.Index) LoggedItems {
+	items := LoggedItems{
+		byID:   make(map[string]types.DeletedItem, len(index.Items)),
+		byName: make(map[string]types.DeletedItem, len(index.Items)),
+	}
+	for _, item := range index.Items {
+		items.byID[item.ID] = item
+		items.byName[filepath.Base(item.CachePath)] = item
+	}
+	return items
+}
+
+// Find returns the cached item a log entry is about, if it is still in the
+// index. Text log entries are matched by the name of their cache path,
+// which is unique and survives the store being moved.
+func (l LoggedItems) Find(entry types.LogEntry) (types.DeletedItem, bool) {
+	if entry.ItemID != "" {
+		item, ok := l.byID[entry.ItemID]
+		return item, ok
+	}
+	if entry.CachePath == "" {
+		return types.DeletedItem{}, false
+	}
+	item, ok := l.byName[filepath.Base(entry.CachePath)]
+	return item, ok
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"vanish/internal/types"
+)
+
+// --- Hooks ---
+
+// Hook events, named like their keys in the [hooks] table
+const (
+	HookPreDelete   = "pre_delete"
+	HookPostDelete  = "post_delete"
+	HookPreRestore  = "pre_restore"
+	HookPostRestore = "post_restore"
+	HookPrePurge    = "pre_purge"
+	HookPostPurge   = "post_purge"
+	HookPostClear   = "post_clear"
+)
+
+// hookCommand returns the command configured for event, "" if none.
+func hookCommand(event string, config types.Config) string {
+	hooks := config.Hooks
+	switch event {
+	case HookPreDelete:
+		return hooks.PreDelete
+	case HookPostDelete:
+		return hooks.PostDelete
+	case HookPreRestore:
+		return hooks.PreRestore
+	case HookPostRestore:
+		return hooks.PostRestore
+	case HookPrePurge:
+		return hooks.PrePurge
+	case HookPostPurge:
+		return hooks.PostPurge
+	case HookPostClear:
+		return hooks.PostClear
+	}
+	return ""
+}
+
+// HasHook reports whether a command will run for event.
+func HasHook(event string, config types.Config, opts types.Options) bool {
+	return !opts.NoHooks && strings.TrimSpace(hookCommand(event, config)) != ""
+}
+
+// IsPreHook reports whether a failure of the event's hook cancels the
+// operation.
+func IsPreHook(event string) bool {
+	return strings.HasPrefix(event, "pre_")
+}
+
+// RunHook runs the command configured for event with sh -c. The affected
+// items are written as a types.HookPayload to its stdin and summarized in
+// VANISH_HOOK_* environment variables. For pre_delete, paths lists what is
+// about to be deleted; otherwise they are taken from items. A non-zero exit
+// or a timeout is returned as an error carrying the hook's stderr.
+func RunHook(event string, paths []string, items []types.DeletedItem, config types.Config, opts types.Options) error {
+	if !HasHook(event, config, opts) {
+		return nil
+	}
+
+	if paths == nil {
+		paths = make([]string, 0, len(items))
+		for _, item := range items {
+			paths = append(paths, item.OriginalPath)
+		}
+	}
+	payload, err := json.Marshal(types.HookPayload{Event: event, Paths: paths, Items: items})
+	if err != nil {
+		return fmt.Errorf("%s hook: failed to encode items: %w", event, err)
+	}
+
+	ctx := context.Background()
+	if config.Hooks.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(config.Hooks.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", hookCommand(event, config))
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second // don't wait on children still holding stderr
+	cmd.Env = append(os.Environ(),
+		"VANISH_HOOK_EVENT="+event,
+		"VANISH_HOOK_COUNT="+strconv.Itoa(len(paths)),
+		"VANISH_HOOK_PATHS="+strings.Join(paths, "\n"),
+		"VANISH_HOOK_IDS="+strings.Join(ids, " "),
+	)
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("%s hook timed out after %ds", event, config.Hooks.Timeout)
+	case strings.TrimSpace(stderr.String()) != "":
+		err = fmt.Errorf("%s hook failed: %s", event, strings.TrimSpace(stderr.String()))
+	default:
+		err = fmt.Errorf("%s hook failed: %v", event, err)
+	}
+	LogEvent("HOOK", event, err, config)
+	return err
+}
+
+// RunHookCmd runs a hook in the background and reports it as a
+// types.HookMsg.
+func RunHookCmd(event string, paths []string, items []types.DeletedItem, config types.Config, opts types.Options) tea.Cmd {
+	return func() tea.Msg {
+		return types.HookMsg{Event: event, Err: RunHook(event, paths, items, config, opts)}
+	}
+}
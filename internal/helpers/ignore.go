@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- Ignore Files ---
+
+// IgnoreFileName is the per-directory ignore file. Its rules use gitignore
+// syntax and apply to everything below the directory it is in.
+const IgnoreFileName = ".vanishignore"
+
+// GlobalIgnorePath returns the ignore file in the config directory, whose
+// rules apply everywhere.
+func GlobalIgnorePath() string {
+	return filepath.Join(GetConfigDir(), "ignore")
+}
+
+type ignoreRule struct {
+	base     string // directory the rule is relative to
+	pattern  string
+	negate   bool   // !pattern re-includes a path
+	dirOnly  bool   // pattern/ only matches directories
+	anchored bool   // contains a slash, so it matches the path relative to base
+	source   string // ignore file the rule came from
+	text     string // the rule as written
+}
+
+// ignoreRules is an ordered rule set; later rules win, like in git.
+type ignoreRules struct {
+	rules []ignoreRule
+}
+
+// loadIgnoreRules returns the global rules plus those of the ignore files in
+// dir and all its ancestors, outermost first.
+func loadIgnoreRules(dir string) *ignoreRules {
+	r := &ignoreRules{}
+	r.addFile(GlobalIgnorePath(), "/")
+
+	var dirs []string
+	for d := dir; ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if d == filepath.Dir(d) {
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		r.addFile(filepath.Join(dirs[i], IgnoreFileName), dirs[i])
+	}
+	return r
+}
+
+// addFile appends the rules of the ignore file at path, if it exists.
+func (r *ignoreRules) addFile(path, base string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base, source: path, text: line}
+		switch {
+		case strings.HasPrefix(line, "!"):
+			rule.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern == "" {
+			continue
+		}
+		r.rules = append(r.rules, rule)
+	}
+}
+
+func (rule ignoreRule) matches(path string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(rule.base, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	if rule.anchored {
+		return MatchGlob(rule.pattern, rel)
+	}
+	return MatchGlob(rule.pattern, filepath.Base(path))
+}
+
+// match returns a description of the rule ignoring the absolute path, or ""
+// if it is not ignored. As in git, everything inside an ignored directory is
+// ignored too and cannot be re-included by a negation.
+func (r *ignoreRules) match(path string, isDir bool) string {
+	if len(r.rules) == 0 {
+		return ""
+	}
+
+	var ancestors []string
+	for d := filepath.Dir(path); d != filepath.Dir(d); d = filepath.Dir(d) {
+		ancestors = append(ancestors, d)
+	}
+	for i := len(ancestors) - 1; i >= -1; i-- {
+		p, dir := path, isDir
+		if i >= 0 {
+			p, dir = ancestors[i], true
+		}
+
+		var last *ignoreRule
+		for j := range r.rules {
+			if r.rules[j].matches(p, dir) {
+				last = &r.rules[j]
+			}
+		}
+		if last != nil && !last.negate {
+			return fmt.Sprintf("listed in %s (%q)", last.source, last.text)
+		}
+	}
+	return ""
+}
+
+// CheckIgnored returns why path is excluded by an ignore file, or "" if it
+// is not. Used by CheckProtected.
+func CheckIgnored(path string) string {
+	absPath := resolveParent(path)
+	stat, err := os.Lstat(absPath)
+	isDir := err == nil && stat.IsDir()
+	return loadIgnoreRules(filepath.Dir(absPath)).match(absPath, isDir)
+}
+
+// FindKeptChildren returns the paths below dir, relative to it, that ignore
+// files exclude. Deleting dir leaves them in place. Ignore files inside dir
+// are honored for their own subtree. Only the outermost excluded path of a
+// subtree is listed. The ignore files of the directories that stay behind
+// are kept as well, so the kept paths remain protected.
+func FindKeptChildren(dir string) []string {
+	absDir := resolveParent(dir)
+	rules := loadIgnoreRules(filepath.Dir(absDir))
+
+	var kept []string
+	filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != absDir && rules.match(path, d.IsDir()) != "" {
+			rel, _ := filepath.Rel(absDir, path)
+			kept = append(kept, rel)
+			return skipEntry(d)
+		}
+		if d.IsDir() {
+			rules.addFile(filepath.Join(path, IgnoreFileName), path)
+		}
+		return nil
+	})
+	if len(kept) == 0 {
+		return nil
+	}
+
+	remaining := map[string]bool{".": true}
+	for _, k := range kept {
+		for d := filepath.Dir(k); d != "."; d = filepath.Dir(d) {
+			remaining[d] = true
+		}
+	}
+	var ignoreFiles []string
+	for d := range remaining {
+		ignoreFile := filepath.Join(d, IgnoreFileName)
+		if _, err := os.Lstat(filepath.Join(absDir, ignoreFile)); err == nil {
+			ignoreFiles = append(ignoreFiles, ignoreFile)
+		}
+	}
+	sort.Strings(ignoreFiles)
+	return append(kept, ignoreFiles...)
+}
+
+// moveDirectoryExcept moves the directory src to dst, leaving the kept paths
+// (relative to src) and the directories leading to them in place.
+func moveDirectoryExcept(src, dst string, kept []string, trace *tracer) error {
+	keep := make(map[string]bool)
+	ancestors := make(map[string]bool)
+	for _, k := range kept {
+		keep[k] = true
+		for d := filepath.Dir(k); d != "."; d = filepath.Dir(d) {
+			ancestors[d] = true
+		}
+	}
+	return moveTreeExcept(src, dst, ".", keep, ancestors, trace)
+}
+
+func moveTreeExcept(src, dst, rel string, keep, ancestors map[string]bool, trace *tracer) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entryRel := filepath.Join(rel, entry.Name())
+		from, to := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+
+		switch {
+		case keep[entryRel]:
+			trace.printf("FS", "keep %s in place", from)
+			continue
+		case ancestors[entryRel]:
+			err = moveTreeExcept(from, to, entryRel, keep, ancestors, trace)
+		case entry.Type()&fs.ModeSymlink != 0:
+			err = moveSymlink(from, to, trace)
+		case entry.IsDir():
+			err = moveDirectory(from, to, trace)
+		default:
+			err = moveFile(from, to, trace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeDirectory moves the contents of src into the existing directory dst
+// and removes src. Nothing is moved if an entry of src already exists in dst
+// as anything but a directory on both sides.
+func mergeDirectory(src, dst string, trace *tracer) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		existing, err := os.Lstat(filepath.Join(dst, rel))
+		if err != nil {
+			return skipEntry(d) // missing in dst, moved as a whole
+		}
+		if !d.IsDir() || !existing.IsDir() {
+			return fmt.Errorf("destination already exists: %s", filepath.Join(dst, rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := mergeTree(src, dst, trace); err != nil {
+		return err
+	}
+	err = os.RemoveAll(src)
+	trace.since("FS", time.Time{}, err, "remove directory %s", src)
+	return err
+}
+
+func mergeTree(src, dst string, trace *tracer) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		from, to := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+		if _, err := os.Lstat(to); err == nil {
+			err = mergeTree(from, to, trace) // both are directories, checked by mergeDirectory
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			err = moveSymlink(from, to, trace)
+		case entry.IsDir():
+			err = moveDirectory(from, to, trace)
+		default:
+			err = moveFile(from, to, trace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 	// "os/exec"
 	"path/filepath"
 	// "runtime"
+	"strings"
+	"time"
 	"vanish/internal/types"
 )
 
@@ -20,12 +22,15 @@ import (
 // at the location specified by the given config. Returns an error if
 // marshalling or writing to file fails.
 func SaveIndex(index types.Index, config types.Config) error {
+	started := time.Now()
 	indexPath := GetIndexPath(config)
 	data, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(indexPath, data, 0644)
+	err = os.WriteFile(indexPath, data, 0644)
+	newTracer(config).since("INDEX", started, err, "save %s: %d items, %d bytes", indexPath, len(index.Items), len(data))
+	return err
 }
 
 // GetIndexPath returns the full path to the index.json file used to
@@ -40,6 +45,7 @@ func GetIndexPath(config types.Config) string {
 // if reading or unmarshalling fails.
 func LoadIndex(config types.Config) (types.Index, error) {
 	var index types.Index
+	started := time.Now()
 	indexPath := GetIndexPath(config)
 
 	data, err := os.ReadFile(indexPath)
@@ -52,6 +58,7 @@ func LoadIndex(config types.Config) (types.Index, error) {
 	}
 
 	err = json.Unmarshal(data, &index)
+	newTracer(config).since("INDEX", started, err, "load %s: %d items, %d bytes", indexPath, len(index.Items), len(data))
 	return index, err
 }
 
@@ -87,3 +94,155 @@ func RemoveFromIndex(itemID string, config types.Config) error {
 	index.Items = remainingItems
 	return SaveIndex(index, config)
 }
+
+// CountPinned returns the number of pinned items in the index.
+func CountPinned(index types.Index) int {
+	count := 0
+	for _, item := range index.Items {
+		if item.Pinned {
+			count++
+		}
+	}
+	return count
+}
+
+// SetPinned pins or unpins every item matching one of the patterns (see
+// MatchesPattern). Returns the items whose state actually changed.
+func SetPinned(patterns []string, pinned bool, config types.Config) ([]types.DeletedItem, error) {
+	index, err := LoadIndex(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []types.DeletedItem
+	for i, item := range index.Items {
+		if item.Pinned == pinned {
+			continue
+		}
+		if MatchesAnyPattern(item, patterns) {
+			index.Items[i].Pinned = pinned
+			changed = append(changed, index.Items[i])
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return changed, SaveIndex(index, config)
+}
+
+// --- Matching Helpers ---
+
+// MatchesPattern reports whether an item is selected by pattern: either the
+// pattern equals the item ID or batch ID, or it is a case-insensitive
+// substring of the original path. Tags are selected with --tag, see HasTags.
+func MatchesPattern(item types.DeletedItem, pattern string) bool {
+	if item.ID == pattern || (item.BatchID != "" && item.BatchID == pattern) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(item.OriginalPath), strings.ToLower(pattern))
+}
+
+// MatchesAnyPattern reports whether any of the patterns selects the item.
+func MatchesAnyPattern(item types.DeletedItem, patterns []string) bool {
+	for _, pattern := range patterns {
+		if MatchesPattern(item, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTags reports whether the item carries every one of the given tags
+// (case-insensitive exact match).
+func HasTags(item types.DeletedItem, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range item.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterItems returns the items matching any of the patterns (all items when
+// no pattern is given) that also carry every one of the tags.
+func FilterItems(items []types.DeletedItem, patterns, tags []string) []types.DeletedItem {
+	var matched []types.DeletedItem
+	for _, item := range items {
+		if len(patterns) > 0 && !MatchesAnyPattern(item, patterns) {
+			continue
+		}
+		if !HasTags(item, tags) {
+			continue
+		}
+		matched = append(matched, item)
+	}
+	return matched
+}
+
+// NormalizeTags trims whitespace and drops empty and duplicate
+// (case-insensitive) tags while keeping their original order.
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// AnnotateItems edits the tags and note of every item matching one of the
+// patterns. addTags are appended, removeTags are dropped and, when note is
+// non-nil, the note is replaced (an empty note clears it). Returns the items
+// that were updated.
+func AnnotateItems(patterns, addTags, removeTags []string, note *string, config types.Config) ([]types.DeletedItem, error) {
+	index, err := LoadIndex(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []types.DeletedItem
+	for i, item := range index.Items {
+		if !MatchesAnyPattern(item, patterns) {
+			continue
+		}
+
+		var tags []string
+		for _, tag := range item.Tags {
+			remove := false
+			for _, r := range removeTags {
+				if strings.EqualFold(tag, r) {
+					remove = true
+					break
+				}
+			}
+			if !remove {
+				tags = append(tags, tag)
+			}
+		}
+		index.Items[i].Tags = NormalizeTags(append(tags, addTags...))
+
+		if note != nil {
+			index.Items[i].Note = strings.TrimSpace(*note)
+		}
+		changed = append(changed, index.Items[i])
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	return changed, SaveIndex(index, config)
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadPaths reads paths from r, one per line, or separated by NUL bytes when
+// nul is set (as written by find -print0 or git ls-files -z). Empty entries
+// are skipped. In line mode a trailing \r is dropped but other whitespace is
+// kept, since it may be part of the file name.
+func ReadPaths(r io.Reader, nul bool) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if nul {
+		scanner.Split(scanNul)
+	}
+
+	var paths []string
+	for scanner.Scan() {
+		path := scanner.Text()
+		if !nul {
+			path = strings.TrimSuffix(path, "\r")
+		}
+		if path == "" {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if err := scanner.Err(); err != nil {
+		return paths, fmt.Errorf("failed to read paths: %w", err)
+	}
+	return paths, nil
+}
+
+// scanNul is a bufio.SplitFunc splitting on NUL bytes.
+func scanNul(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
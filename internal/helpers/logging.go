@@ -5,101 +5,274 @@
 package helpers
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strings"
 	"time"
 	"vanish/internal/types"
 )
 
 // --- Logging ---
 
-// LogOperation writes a log entry for the given operation and DeletedItem
-// to the configured logging directory. If the log directory or file does
-// not exist, it attempts to create them.
-func LogOperation(operation string, item types.DeletedItem, config types.Config) error {
+// LogFileName is the operation log inside the logging directory.
+const LogFileName = "vanish.log"
+
+// Outcomes recorded in log entries
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Log levels, from the most to the least verbose
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var levelRanks = map[string]int{LevelDebug: 0, LevelInfo: 1, LevelWarn: 2, LevelError: 3}
+
+// ValidLogLevel reports whether level is one of the log levels.
+func ValidLogLevel(level string) bool {
+	_, ok := levelRanks[level]
+	return ok
+}
+
+// LevelEnabled reports whether records of the given level are written with
+// config. An unknown configured level counts as info.
+func LevelEnabled(level string, config types.Config) bool {
 	if !config.Logging.Enabled {
+		return false
+	}
+	threshold, ok := levelRanks[config.Logging.Level]
+	if !ok {
+		threshold = levelRanks[LevelInfo]
+	}
+	return levelRanks[level] >= threshold
+}
+
+// WithVerbosity returns config with the log level lowered for -v (info) or
+// -vv (debug). A more verbose configured level is kept.
+func WithVerbosity(config types.Config, verbosity int) types.Config {
+	level := ""
+	switch {
+	case verbosity >= 2:
+		level = LevelDebug
+	case verbosity == 1:
+		level = LevelInfo
+	default:
+		return config
+	}
+	if !LevelEnabled(level, config) {
+		config.Logging.Level = level
+	}
+	return config
+}
+
+// WriteLog appends entry to vanish.log in the configured format, one JSON
+// object per line or the older text lines, rotating the log first when it
+// has grown past max_size. The time, user and PID are filled in here.
+// Entries without a level are info, or error when they failed. It does
+// nothing when logging is disabled or the level is below the configured
+// one.
+func WriteLog(entry types.LogEntry, config types.Config) error {
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeOK
+	}
+	if entry.Level == "" {
+		entry.Level = LevelInfo
+		if entry.Outcome == OutcomeError {
+			entry.Level = LevelError
+		}
+	}
+	if !LevelEnabled(entry.Level, config) {
 		return nil
 	}
 
+	entry.Time = time.Now()
+	entry.User = logUser()
+	entry.PID = os.Getpid()
+
 	logDir := ExpandPath(config.Logging.Directory)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	logPath := filepath.Join(logDir, "vanish.log")
+	unlock, err := lockLog(logDir)
+	if err != nil {
+		return fmt.Errorf("failed to lock log file: %w", err)
+	}
+	defer unlock()
+
+	// A failed rotation must not lose the entry, it is reported afterwards
+	rotateErr := rotateLog(logDir, config)
+
+	// The chain is continued under the lock, so records are numbered in
+	// the order they are written
+	var line string
+	var chain auditState
+	if config.Logging.Audit {
+		chain, line, err = chainEntry(logDir, entry)
+	} else {
+		line, err = formatLog(entry, config)
+	}
+	if err != nil {
+		return err
+	}
+
+	logPath := filepath.Join(logDir, LogFileName)
 	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
 	defer logFile.Close()
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	itemType := "FILE"
-	if item.IsDirectory {
-		itemType = "DIR"
+	if _, err := logFile.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write to log file: %w", err)
 	}
 
-	logEntry := fmt.Sprintf("%s [%s] %s: %s -> %s\n",
-		timestamp,
-		itemType,
-		operation,
-		item.OriginalPath,
-		item.CachePath,
-	)
-
-	if _, err := logFile.WriteString(logEntry); err != nil {
-		return fmt.Errorf("failed to write to log file: %w", err)
+	if config.Logging.Audit {
+		if err := saveAuditState(logDir, chain); err != nil {
+			return err
+		}
 	}
+	return rotateErr
+}
 
-	return nil
+// formatLog renders entry as a line in the configured format.
+func formatLog(entry types.LogEntry, config types.Config) (string, error) {
+	if config.Logging.Format == "text" {
+		return formatTextLog(entry), nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode log entry: %w", err)
+	}
+	return string(data), nil
 }
-func LogSimpleOperation(operation, message string, config types.Config) error {
-	if !config.Logging.Enabled {
-		return nil
+
+// LogOperation logs an operation on a cached item. A non-nil err marks the
+// operation as failed. In audit mode a successful DELETE or RESTORE also
+// records the content hash of the item where it ended up.
+func LogOperation(operation string, item types.DeletedItem, err error, config types.Config) error {
+	entry := types.LogEntry{
+		Operation:    operation,
+		ItemID:       item.ID,
+		BatchID:      item.BatchID,
+		OriginalPath: item.OriginalPath,
+		CachePath:    item.CachePath,
+		Size:         item.Size,
+		IsDirectory:  item.IsDirectory,
 	}
+	setOutcome(&entry, err)
 
-	logDir := ExpandPath(config.Logging.Directory)
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
+	if config.Logging.Audit && config.Logging.Enabled && err == nil {
+		switch operation {
+		case "DELETE":
+			entry.ContentHash, _ = ContentHash(item.CachePath)
+		case "RESTORE":
+			entry.ContentHash, _ = ContentHash(item.OriginalPath)
+		}
 	}
+	return WriteLog(entry, config)
+}
 
-	logPath := filepath.Join(logDir, "vanish.log")
-	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// LogEvent logs an operation that is not about a single item, such as
+// clearing the cache or a failed hook.
+func LogEvent(operation, message string, err error, config types.Config) error {
+	entry := types.LogEntry{Operation: operation, Message: message}
+	setOutcome(&entry, err)
+	return WriteLog(entry, config)
+}
+
+// LogWarning logs a problem that did not stop the operation, such as a
+// desktop notification that could not be sent.
+func LogWarning(operation, message string, err error, config types.Config) error {
+	entry := types.LogEntry{Level: LevelWarn, Operation: operation, Message: message}
+	setOutcome(&entry, err)
+	return WriteLog(entry, config)
+}
+
+func setOutcome(entry *types.LogEntry, err error) {
+	entry.Outcome = OutcomeOK
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		entry.Outcome = OutcomeError
+		entry.Error = err.Error()
 	}
-	defer logFile.Close()
+}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logEntry := fmt.Sprintf("%s [%s] %s\n", timestamp, operation, message)
+// formatTextLog renders entry as the text lines vanish wrote before the
+// JSON format, e.g. "2006-01-02 15:04:05 [FILE] DELETE: /a -> /cache/a".
+// Records other than info are prefixed with their level.
+func formatTextLog(entry types.LogEntry) string {
+	var line strings.Builder
+	line.WriteString(entry.Time.Format("2006-01-02 15:04:05"))
+	if entry.Level != LevelInfo {
+		fmt.Fprintf(&line, " %s", strings.ToUpper(entry.Level))
+	}
 
-	if _, err := logFile.WriteString(logEntry); err != nil {
-		return fmt.Errorf("failed to write to log file: %w", err)
+	if entry.OriginalPath != "" {
+		itemType := "FILE"
+		if entry.IsDirectory {
+			itemType = "DIR"
+		}
+		fmt.Fprintf(&line, " [%s] %s: %s -> %s", itemType, entry.Operation, entry.OriginalPath, entry.CachePath)
+	} else {
+		fmt.Fprintf(&line, " [%s] %s", entry.Operation, entry.Message)
 	}
 
-	return nil
+	if entry.DurationMS > 0 {
+		fmt.Fprintf(&line, " (%.3fms)", entry.DurationMS)
+	}
+	if entry.Outcome == OutcomeError {
+		fmt.Fprintf(&line, " (error: %s)", entry.Error)
+	}
+	return line.String()
 }
 
-// logClearOperation logs the cache clear operation
-func logClearOperation(config types.Config) error {
-	logDir := ExpandPath(config.Logging.Directory)
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
-	}
+// --- Debug Tracing ---
 
-	logPath := filepath.Join(logDir, "vanish.log")
-	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+// tracer writes debug records of the filesystem calls made while moving
+// items in and out of the cache. A nil tracer, returned when the debug
+// level is off, traces nothing, so callers don't have to check.
+type tracer struct {
+	config types.Config
+}
+
+func newTracer(config types.Config) *tracer {
+	if !LevelEnabled(LevelDebug, config) {
+		return nil
 	}
-	defer logFile.Close()
+	return &tracer{config: config}
+}
+
+// printf logs a debug record for operation.
+func (t *tracer) printf(operation, format string, args ...any) {
+	t.since(operation, time.Time{}, nil, format, args...)
+}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	logEntry := fmt.Sprintf("%s CLEAR_ALL Cache cleared\n", timestamp)
-	if _, err := logFile.WriteString(logEntry); err != nil {
-		return fmt.Errorf("failed to write to log: %w", err)
+// since logs a debug record for a call that began at started, with its
+// duration and err as the outcome. A zero started records no duration.
+func (t *tracer) since(operation string, started time.Time, err error, format string, args ...any) {
+	if t == nil {
+		return
+	}
+	entry := types.LogEntry{Level: LevelDebug, Operation: operation, Message: fmt.Sprintf(format, args...)}
+	if !started.IsZero() {
+		entry.DurationMS = float64(time.Since(started).Microseconds()) / 1000
 	}
+	setOutcome(&entry, err)
+	WriteLog(entry, t.config)
+}
 
-	return nil
+// logUser returns the name of the user running vanish.
+func logUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
 }
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"vanish/internal/types"
+)
+
+// --- Log Rotation ---
+
+// rotatedLogTime is the timestamp in the name of a rotated log. It sorts in
+// the order the logs were rotated.
+const rotatedLogTime = "2006-01-02T15-04-05.000"
+
+// lockLog takes an exclusive lock on vanish.log.lock in logDir, so that
+// concurrent vx processes never write while another one rotates. The
+// returned function releases the lock.
+func lockLog(logDir string) (func(), error) {
+	lockFile, err := os.OpenFile(filepath.Join(logDir, LogFileName+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		lockFile.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		lockFile.Close()
+	}, nil
+}
+
+// logsPruned is set once this process has pruned the rotated logs, so
+// those past max_age are removed even when the log is not rotated.
+var logsPruned atomic.Bool
+
+// rotateLog renames vanish.log to vanish-<time>.log once it has reached
+// max_size megabytes, records the rotation in audit mode, gzips it if
+// compress is set and removes the rotated logs beyond max_files and
+// max_age. Without a rotation they are pruned on the first write of the
+// process. The caller holds the log lock.
+func rotateLog(logDir string, config types.Config) error {
+	maxSize := int64(config.Logging.MaxSize) * 1024 * 1024
+	logPath := filepath.Join(logDir, LogFileName)
+	info, err := os.Stat(logPath)
+	if maxSize <= 0 || err != nil || info.Size() < maxSize {
+		if logsPruned.CompareAndSwap(false, true) {
+			return pruneLogs(logDir, config)
+		}
+		return nil
+	}
+
+	rotated := filepath.Join(logDir, "vanish-"+time.Now().Format(rotatedLogTime)+".log")
+	if err := os.Rename(logPath, rotated); err != nil {
+		return fmt.Errorf("failed to rotate log: %w", err)
+	}
+	if config.Logging.Audit {
+		if err := recordAuditRotation(logDir); err != nil {
+			return err
+		}
+	}
+	if config.Logging.Compress {
+		if err := compressLog(rotated); err != nil {
+			return fmt.Errorf("failed to compress rotated log: %w", err)
+		}
+	}
+	logsPruned.Store(true)
+	return pruneLogs(logDir, config)
+}
+
+// compressLog replaces path with path.gz.
+func compressLog(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+// pruneLogs removes rotated logs older than max_age days, then the oldest
+// ones beyond max_files.
+func pruneLogs(logDir string, config types.Config) error {
+	rotated := rotatedLogs(logDir)
+
+	var kept []string
+	cutoff := time.Now().Add(-time.Duration(config.Logging.MaxAge) * 24 * time.Hour)
+	for _, path := range rotated {
+		info, err := os.Stat(path)
+		if config.Logging.MaxAge > 0 && err == nil && info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove old log: %w", err)
+			}
+			continue
+		}
+		kept = append(kept, path)
+	}
+
+	if config.Logging.MaxFiles <= 0 {
+		return nil
+	}
+	for len(kept) > config.Logging.MaxFiles {
+		if err := os.Remove(kept[0]); err != nil {
+			return fmt.Errorf("failed to remove old log: %w", err)
+		}
+		kept = kept[1:]
+	}
+	return nil
+}
+
+// rotatedLogs returns the rotated logs in logDir, oldest first.
+func rotatedLogs(logDir string) []string {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil
+	}
+
+	var logs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, "vanish-") &&
+			(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			logs = append(logs, filepath.Join(logDir, name))
+		}
+	}
+	sort.Strings(logs)
+	return logs
+}
+
+// LogFiles returns the rotated logs followed by the current vanish.log,
+// oldest first, so reading them in order gives the whole history.
+func LogFiles(config types.Config) []string {
+	logDir := ExpandPath(config.Logging.Directory)
+	logs := rotatedLogs(logDir)
+	if _, err := os.Stat(filepath.Join(logDir, LogFileName)); err == nil {
+		logs = append(logs, filepath.Join(logDir, LogFileName))
+	}
+	return logs
+}
+
+// gzipLog closes both the gzip stream and the file under it.
+type gzipLog struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipLog) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// OpenLogFile opens one of the LogFiles for reading, decompressing rotated
+// logs that were gzipped.
+func OpenLogFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return gzipLog{Reader: reader, file: file}, nil
+}
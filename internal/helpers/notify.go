@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"vanish/internal/types"
+)
+
+// --- Desktop Notifications ---
+
+// The freedesktop notification service on the session bus
+const (
+	notifyService   = "org.freedesktop.Notifications"
+	notifyPath      = "/org/freedesktop/Notifications"
+	notifyMethod    = notifyService + ".Notify"
+	notifyTimeout   = 2 * time.Second
+	urgencyNormal   = byte(1)
+	urgencyCritical = byte(2)
+)
+
+// SendNotification shows a desktop notification through the
+// org.freedesktop.Notifications service of the session bus. It does nothing
+// unless desktop_enabled is set and notify_errors or notify_success allows
+// the kind of message. Failures are logged and returned.
+func SendNotification(title, message string, isError bool, config types.Config) error {
+	notifications := config.Notifications
+	if !notifications.DesktopEnabled {
+		return nil
+	}
+	if (isError && !notifications.NotifyErrors) || (!isError && !notifications.NotifySuccess) {
+		return nil
+	}
+
+	if err := sendNotification(title, message, isError); err != nil {
+		LogWarning("NOTIFY", title, err, config)
+		return err
+	}
+	return nil
+}
+
+func sendNotification(title, message string, isError bool) error {
+	address, err := sessionBusAddress()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	conn, err := dbus.Connect(address, dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+	defer conn.Close()
+
+	urgency := urgencyNormal
+	icon := "user-trash"
+	if isError {
+		urgency = urgencyCritical
+		icon = "dialog-error"
+	}
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(urgency)}
+
+	obj := conn.Object(notifyService, notifyPath)
+	call := obj.CallWithContext(ctx, notifyMethod, 0,
+		"vanish", uint32(0), icon, title, message, []string{}, hints, int32(-1))
+	if call.Err != nil {
+		return fmt.Errorf("notification service: %w", call.Err)
+	}
+	return nil
+}
+
+// sessionBusAddress finds the session bus without ever starting one, which
+// the D-Bus autolaunch would do.
+func sessionBusAddress() (string, error) {
+	if address := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); address != "" {
+		return address, nil
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		socket := filepath.Join(runtimeDir, "bus")
+		if _, err := os.Stat(socket); err == nil {
+			return "unix:path=" + socket, nil
+		}
+	}
+	return "", fmt.Errorf("no session bus found")
+}
+
+// IsLongRunning reports whether an operation took long enough to be worth
+// a notification on completion.
+func IsLongRunning(elapsed time.Duration, config types.Config) bool {
+	return elapsed >= time.Duration(config.Notifications.MinSeconds)*time.Second
+}
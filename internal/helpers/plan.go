@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"vanish/internal/types"
+)
+
+// --- Dry Run Planning ---
+//
+// The Plan* functions only read the filesystem and the index. They never
+// create, move or remove anything and never write to the log.
+
+// PlanOperation builds the dry-run plan for any operation. args are the
+// filenames for delete, the patterns for restore and the days for purge.
+func PlanOperation(operation string, args []string, config types.Config, opts types.Options) (types.Plan, error) {
+	switch operation {
+	case "clear":
+		return PlanClear(config)
+	case "purge":
+		if len(args) == 0 {
+			return types.Plan{}, fmt.Errorf("purge requires number of days")
+		}
+		return PlanPurge(args[0], config)
+	case "restore":
+		return PlanRestore(args, opts.Tags, config)
+	default: // delete
+		return PlanDelete(args, config, opts)
+	}
+}
+
+// PlanOperationCmd wraps PlanOperation for the TUI.
+func PlanOperationCmd(operation string, args []string, config types.Config, opts types.Options) tea.Cmd {
+	return func() tea.Msg {
+		plan, err := PlanOperation(operation, args, config, opts)
+		if err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		return types.PlanMsg{Plan: plan}
+	}
+}
+
+// PlanDelete reports where each file would be moved, which ones would be
+// skipped or refused as protected, and which cached items the automatic
+// cleanup afterwards would remove.
+func PlanDelete(filenames []string, config types.Config, opts types.Options) (types.Plan, error) {
+	plan := types.Plan{Operation: "delete", Actions: []types.PlanAction{}}
+	cacheDir := ExpandPath(config.Cache.Directory)
+	now := time.Now()
+
+	for _, filename := range filenames {
+		absPath, err := filepath.Abs(filename)
+		if err != nil {
+			absPath = filename
+		}
+
+		stat, err := os.Lstat(filename)
+		if err != nil {
+			plan.Actions = append(plan.Actions, types.PlanAction{Action: "skip", Path: absPath, Reason: "does not exist"})
+			continue
+		}
+
+		action := types.PlanAction{
+			Action:      "move",
+			Path:        absPath,
+			IsDirectory: stat.IsDir(),
+			Size:        stat.Size(),
+		}
+		if stat.IsDir() {
+			action.Size, _ = GetDirectorySize(filename)
+		}
+
+		if protection := CheckProtected(filename, config); protection != nil {
+			if protection.IsRefused(opts.AllowProtected) {
+				plan.Actions = append(plan.Actions, types.PlanAction{
+					Action: "refuse", Path: absPath, IsDirectory: stat.IsDir(), Reason: protection.Reason,
+				})
+				continue
+			}
+			action.Reason = "protected (" + protection.Reason + "), overridden"
+		}
+		if stat.IsDir() && !opts.AllowProtected {
+			action.Kept = FindKeptChildren(filename)
+		}
+		action.Git = CheckGit(filename)
+
+		var cacheFilename string
+		action.ID, cacheFilename = cacheName(filename, now)
+		action.Target = filepath.Join(cacheDir, cacheFilename)
+		plan.Actions = append(plan.Actions, action)
+		plan.TotalBytes += action.Size
+	}
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return plan, fmt.Errorf("error loading index: %w", err)
+	}
+	cutoff := now.Add(-time.Duration(config.Cache.Days) * 24 * time.Hour)
+	plan.Expiring = planExpired(index, cutoff, "cleanup")
+
+	return plan, nil
+}
+
+// PlanRestore reports where each matching item would be restored to,
+// including renames caused by conflicts and items missing from the cache.
+func PlanRestore(patterns, tags []string, config types.Config) (types.Plan, error) {
+	plan := types.Plan{Operation: "restore", Actions: []types.PlanAction{}}
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return plan, fmt.Errorf("error loading index: %w", err)
+	}
+
+	for _, item := range FilterItems(index.Items, patterns, tags) {
+		action := types.PlanAction{
+			Action:      "restore",
+			Path:        item.CachePath,
+			Target:      restoreTarget(item),
+			ID:          item.ID,
+			IsDirectory: item.IsDirectory,
+			Size:        item.Size,
+		}
+
+		if _, err := os.Lstat(item.CachePath); err != nil {
+			action.Action = "skip"
+			action.Target = item.OriginalPath
+			action.Reason = "cached file not found"
+			plan.Actions = append(plan.Actions, action)
+			continue
+		}
+
+		if action.Target != item.OriginalPath {
+			action.Conflict = true
+			action.Reason = fmt.Sprintf("%s already exists", item.OriginalPath)
+		} else if len(item.KeptPaths) > 0 {
+			action.Kept = item.KeptPaths
+			if _, err := os.Lstat(item.OriginalPath); err == nil {
+				action.Reason = "merged back around the kept paths"
+			}
+		}
+		plan.Actions = append(plan.Actions, action)
+		plan.TotalBytes += action.Size
+	}
+
+	return plan, nil
+}
+
+// PlanPurge reports which items a purge of the given number of days would remove.
+func PlanPurge(daysStr string, config types.Config) (types.Plan, error) {
+	plan := types.Plan{Operation: "purge", Actions: []types.PlanAction{}}
+
+	days, err := strconv.Atoi(daysStr)
+	if err != nil {
+		return plan, fmt.Errorf("invalid days value: %s", daysStr)
+	}
+	if days <= 0 {
+		return plan, fmt.Errorf("days must be positive, got: %d", days)
+	}
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return plan, fmt.Errorf("error loading index: %w", err)
+	}
+
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	plan.Actions = append(plan.Actions, planExpired(index, cutoff, "purge")...)
+	for _, action := range plan.Actions {
+		if action.Action == "purge" {
+			plan.TotalBytes += action.Size
+		}
+	}
+	return plan, nil
+}
+
+// PlanClear reports every item a clear would permanently delete.
+func PlanClear(config types.Config) (types.Plan, error) {
+	plan := types.Plan{Operation: "clear", Actions: []types.PlanAction{}}
+
+	index, err := LoadIndex(config)
+	if err != nil {
+		return plan, fmt.Errorf("error loading index: %w", err)
+	}
+
+	for _, item := range index.Items {
+		action := itemAction("clear", item)
+		if item.Pinned {
+			action.Reason = "pinned"
+		}
+		plan.Actions = append(plan.Actions, action)
+		plan.TotalBytes += item.Size
+	}
+	return plan, nil
+}
+
+// planExpired lists the items deleted before cutoff. Expired-looking items
+// that are pinned are reported as skipped.
+func planExpired(index types.Index, cutoff time.Time, actionName string) []types.PlanAction {
+	var actions []types.PlanAction
+	for _, item := range index.Items {
+		if !item.DeleteDate.Before(cutoff) {
+			continue
+		}
+		action := itemAction(actionName, item)
+		if item.Pinned {
+			action.Action = "skip"
+			action.Reason = "pinned"
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+func itemAction(actionName string, item types.DeletedItem) types.PlanAction {
+	return types.PlanAction{
+		Action:      actionName,
+		Path:        item.OriginalPath,
+		Target:      item.CachePath,
+		ID:          item.ID,
+		IsDirectory: item.IsDirectory,
+		Size:        item.Size,
+	}
+}
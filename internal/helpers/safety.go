@@ -0,0 +1,228 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"vanish/internal/types"
+)
+
+// --- Protected Paths ---
+
+// Protection describes why a path must not be vanished. Hard protections
+// can never be overridden; soft ones are lifted by --allow-protected.
+type Protection struct {
+	Reason string
+	Hard   bool
+}
+
+// CheckProtected returns the protection that applies to path, or nil if the
+// path may be moved to the cache. The built-in deny-list covers the root
+// directory, the home directory, anything containing or inside the cache,
+// anything containing the log directory and mount points. On top of that,
+// every glob in [safety] protected is checked: globs without a slash match
+// the base name at any depth, others match the full path (see MatchGlob).
+// Finally, paths excluded by a .vanishignore file are protected as well.
+func CheckProtected(path string, config types.Config) *Protection {
+	return checkProtected(resolveParent(path), config, nil)
+}
+
+// checkProtected is CheckProtected for a resolved path. The mount table and
+// ignore rules come from cache, or are read for this path when it is nil.
+func checkProtected(absPath string, config types.Config, cache *protectionCache) *Protection {
+	if absPath == "/" {
+		return &Protection{Reason: "root directory", Hard: true}
+	}
+
+	cacheDir := resolveParent(ExpandPath(config.Cache.Directory))
+	if absPath == cacheDir {
+		return &Protection{Reason: "the vanish cache itself", Hard: true}
+	}
+	if isSameOrAncestor(absPath, cacheDir) {
+		return &Protection{Reason: "contains the vanish cache", Hard: true}
+	}
+	if isSameOrAncestor(cacheDir, absPath) {
+		return &Protection{Reason: "inside the vanish cache, use --purge or --clear", Hard: true}
+	}
+
+	if homeDir, err := os.UserHomeDir(); err == nil && absPath == resolveParent(homeDir) {
+		return &Protection{Reason: "home directory"}
+	}
+
+	if config.Logging.Directory != "" {
+		logDir := resolveParent(ExpandPath(config.Logging.Directory))
+		if isSameOrAncestor(absPath, logDir) {
+			return &Protection{Reason: "contains the vanish logs"}
+		}
+	}
+
+	if cache.isMountPoint(absPath) {
+		return &Protection{Reason: "mount point"}
+	}
+
+	for _, pattern := range config.Safety.Protected {
+		if matchProtectedGlob(pattern, absPath) {
+			return &Protection{Reason: "matches protected pattern " + strconv.Quote(pattern)}
+		}
+	}
+
+	if reason := cache.ignored(absPath); reason != "" {
+		return &Protection{Reason: reason}
+	}
+
+	return nil
+}
+
+// IsRefused reports whether the protection blocks the operation given the
+// --allow-protected override.
+func (p *Protection) IsRefused(allowProtected bool) bool {
+	return p != nil && (p.Hard || !allowProtected)
+}
+
+func matchProtectedGlob(pattern, absPath string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		return MatchGlob(pattern, filepath.Base(absPath))
+	}
+	if strings.HasPrefix(pattern, "~/") || (!filepath.IsAbs(pattern) && !strings.HasPrefix(pattern, "**")) {
+		pattern = ExpandPath(pattern)
+	}
+	return MatchGlob(pattern, absPath)
+}
+
+// resolveParent returns the absolute, cleaned form of path with symlinks in
+// its parent directories resolved. The final component is left alone since
+// vanish moves symlinks themselves rather than their targets.
+func resolveParent(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if absPath == "/" {
+		return absPath
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(absPath)); err == nil {
+		return filepath.Join(dir, filepath.Base(absPath))
+	}
+	return absPath
+}
+
+// isSameOrAncestor reports whether dir equals path or contains it.
+func isSameOrAncestor(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, "../"))
+}
+
+// protectionCache holds what CheckProtected reads from disk, the mount
+// table and the ignore rules, so a walk reads them once instead of for
+// every entry. A nil cache reads them for each check.
+type protectionCache struct {
+	mounts map[string]bool // nil when /proc/self/mountinfo is unavailable
+	rules  *ignoreRules    // those of root's ancestors and the directories entered
+}
+
+// newProtectionCache reads the mount table and the ignore rules applying
+// to the resolved path root.
+func newProtectionCache(root string) *protectionCache {
+	mounts, _ := readMountPoints()
+	return &protectionCache{mounts: mounts, rules: loadIgnoreRules(filepath.Dir(root))}
+}
+
+// enterDir adds the ignore file of dir, whose rules apply below it. Rules
+// of directories left since never match again, as they only apply below
+// their own directory.
+func (c *protectionCache) enterDir(dir string) {
+	c.rules.addFile(filepath.Join(dir, IgnoreFileName), dir)
+}
+
+func (c *protectionCache) isMountPoint(path string) bool {
+	if c == nil {
+		mounts, _ := readMountPoints()
+		return isMountPointIn(path, mounts)
+	}
+	return isMountPointIn(path, c.mounts)
+}
+
+func (c *protectionCache) ignored(path string) string {
+	if c == nil {
+		return CheckIgnored(path)
+	}
+	stat, err := os.Lstat(path)
+	return c.rules.match(path, err == nil && stat.IsDir())
+}
+
+// isMountPointIn reports whether path is the root of a mounted filesystem.
+// It consults mounts, read from /proc/self/mountinfo, and without them
+// compares the device of the path with that of its parent.
+func isMountPointIn(path string, mounts map[string]bool) bool {
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+
+	if mounts != nil {
+		return mounts[path]
+	}
+
+	parentInfo, err := os.Lstat(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	stat, ok1 := info.Sys().(*syscall.Stat_t)
+	parentStat, ok2 := parentInfo.Sys().(*syscall.Stat_t)
+	return ok1 && ok2 && stat.Dev != parentStat.Dev
+}
+
+// readMountPoints parses /proc/self/mountinfo. The second return value is
+// false when the file is unavailable (e.g. on macOS).
+func readMountPoints() (map[string]bool, bool) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	mounts := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mounts[unescapeMountPath(fields[4])] = true
+	}
+	return mounts, true
+}
+
+// unescapeMountPath decodes the octal escapes (e.g. \040 for space) used in
+// /proc/self/mountinfo.
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // IsSymlink checks if the given path is a symbolic link
@@ -21,6 +22,10 @@ func IsSymlink(path string) (bool, error) {
 // MoveSymlink handles moving a symbolic link to cache
 // It reads the link target and recreates the symlink at the destination
 func MoveSymlink(src, dst string) error {
+	return moveSymlink(src, dst, nil)
+}
+
+func moveSymlink(src, dst string, trace *tracer) error {
 	// Read the link target
 	linkTarget, err := os.Readlink(src)
 	if err != nil {
@@ -28,12 +33,16 @@ func MoveSymlink(src, dst string) error {
 	}
 
 	// Create the symlink at destination
-	if err := os.Symlink(linkTarget, dst); err != nil {
+	err = os.Symlink(linkTarget, dst)
+	trace.since("FS", time.Time{}, err, "symlink %s -> %s", dst, linkTarget)
+	if err != nil {
 		return fmt.Errorf("failed to create symlink: %w", err)
 	}
 
 	// Remove the original symlink
-	if err := os.Remove(src); err != nil {
+	err = os.Remove(src)
+	trace.since("FS", time.Time{}, err, "remove %s", src)
+	if err != nil {
 		return fmt.Errorf("failed to remove original symlink: %w", err)
 	}
 
@@ -57,6 +66,10 @@ func GetFileInfo(path string) (os.FileInfo, bool, error) {
 
 // RestoreSymlink restores a symbolic link from cache back to its original location
 func RestoreSymlink(cachePath, originalPath string) error {
+	return restoreSymlink(cachePath, originalPath, nil)
+}
+
+func restoreSymlink(cachePath, originalPath string, trace *tracer) error {
 	// Read the link target from cache
 	linkTarget, err := os.Readlink(cachePath)
 	if err != nil {
@@ -70,12 +83,16 @@ func RestoreSymlink(cachePath, originalPath string) error {
 	}
 
 	// Recreate the symlink at original location
-	if err := os.Symlink(linkTarget, originalPath); err != nil {
+	err = os.Symlink(linkTarget, originalPath)
+	trace.since("FS", time.Time{}, err, "symlink %s -> %s", originalPath, linkTarget)
+	if err != nil {
 		return fmt.Errorf("failed to restore symlink: %w", err)
 	}
 
 	// Remove from cache
-	if err := os.Remove(cachePath); err != nil {
+	err = os.Remove(cachePath)
+	trace.since("FS", time.Time{}, err, "remove %s", cachePath)
+	if err != nil {
 		return fmt.Errorf("failed to remove cached symlink: %w", err)
 	}
 
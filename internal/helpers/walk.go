@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: GPL-3.0-or-later
+// Copyright (C) 2026 Dawood Khan
+
+package helpers
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"vanish/internal/types"
+)
+
+// --- Pattern Walking ---
+
+// MatchOptions controls which paths FindMatches collects.
+type MatchOptions struct {
+	Match          []string // a path is collected if it matches any of these
+	Exclude        []string // matching paths are skipped along with everything below them
+	Filter         EntryFilter
+	OneFileSystem  bool // stay on the filesystem of each root
+	AllowProtected bool // lift soft protections, see CheckProtected
+}
+
+// FindMatches walks every root and returns the paths matching a Match glob,
+// passing the Filter and matching no Exclude glob. Globs are matched against
+// the path relative to its root; globs without a slash match the base name
+// at any depth, others use MatchGlob, so "**/__pycache__" and "__pycache__"
+// are equivalent. A collected directory is moved as a whole and not
+// descended into, a matching one rejected by the Filter is. Without Match
+// globs only the direct entries of each root are candidates. Protected paths
+// are neither collected nor descended into; the mount table and ignore files
+// are read once per root for that.
+func FindMatches(roots []string, opts MatchOptions, config types.Config) ([]string, error) {
+	var matches []string
+	topLevelOnly := len(opts.Match) == 0
+	now := time.Now()
+
+	for _, root := range roots {
+		rootDev, err := deviceOf(root)
+		if err != nil {
+			return nil, fmt.Errorf("cannot walk %s: %v", root, err)
+		}
+		absRoot := resolveParent(root)
+		protection := newProtectionCache(absRoot)
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // unreadable entries are skipped, like find does
+			}
+
+			rel, _ := filepath.Rel(root, path)
+			absPath := filepath.Join(absRoot, rel)
+			if rel == "." {
+				if d.IsDir() {
+					protection.enterDir(absPath)
+					return nil
+				}
+				rel = filepath.Base(path) // a file given as root
+			}
+
+			if matchWalkGlobs(opts.Exclude, rel) || checkProtected(absPath, config, protection).IsRefused(opts.AllowProtected) {
+				return skipEntry(d)
+			}
+
+			if d.IsDir() && opts.OneFileSystem {
+				if dev, err := deviceOf(path); err != nil || dev != rootDev {
+					return filepath.SkipDir
+				}
+			}
+			if d.IsDir() {
+				protection.enterDir(absPath)
+			}
+
+			if !topLevelOnly && !matchWalkGlobs(opts.Match, rel) {
+				return nil
+			}
+			if !opts.Filter.IsZero() {
+				info, err := d.Info()
+				if err != nil || !opts.Filter.Matches(path, info, now) {
+					if topLevelOnly {
+						return skipEntry(d)
+					}
+					return nil
+				}
+			}
+			// A matched tree moves in one piece, so it must not span filesystems
+			if d.IsDir() && opts.OneFileSystem && FindOtherDevice(path) != "" {
+				return filepath.SkipDir
+			}
+			matches = append(matches, path)
+			return skipEntry(d)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot walk %s: %v", root, err)
+		}
+	}
+
+	return matches, nil
+}
+
+// matchWalkGlobs reports whether the relative path matches any of the globs.
+func matchWalkGlobs(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if !strings.Contains(strings.Trim(glob, "/"), "/") {
+			if ok, _ := filepath.Match(glob, filepath.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if MatchGlob(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipEntry stops WalkDir from descending into d if it is a directory.
+func skipEntry(d fs.DirEntry) error {
+	if d.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// FindOtherDevice returns the first directory below root that lives on a
+// different filesystem than root itself, or "" if there is none.
+func FindOtherDevice(root string) string {
+	rootDev, err := deviceOf(root)
+	if err != nil {
+		return ""
+	}
+
+	found := ""
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if dev, err := deviceOf(path); err == nil && dev != rootDev {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// deviceOf returns the device number of the filesystem holding path.
+func deviceOf(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("no device information for %s", path)
+	}
+	return uint64(stat.Dev), nil
+}
@@ -5,35 +5,68 @@
 package tui
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
+	"strings"
 	"time"
 
+	"golang.org/x/term"
+
 	"vanish/internal/helpers"
 	"vanish/internal/types"
 )
 
 // ExecuteHeadless performs operations without the TUI
-func ExecuteHeadless(filenames []string, operation string, cfg types.Config) error {
+func ExecuteHeadless(filenames []string, operation string, cfg types.Config, opts types.Options) error {
+	if opts.DryRun {
+		return executeDryRunHeadless(filenames, operation, cfg, opts)
+	}
+
 	switch operation {
 	case "clear":
-		return executeClearHeadless(cfg)
+		return executeClearHeadless(cfg, opts)
 	case "purge":
 		if len(filenames) == 0 {
 			return fmt.Errorf("purge requires number of days")
 		}
-		return executePurgeHeadless(filenames[0], cfg)
-		// TODO : Add restore
-	// case "restore":
-	// 	return executeRestoreHeadless(filenames, cfg)
+		return executePurgeHeadless(filenames[0], cfg, opts)
+	case "restore":
+		return executeRestoreHeadless(filenames, cfg, opts)
 	default: // delete
-		return executeDeleteHeadless(filenames, cfg)
+		return executeDeleteHeadless(filenames, cfg, opts)
 	}
 }
 
-func executeClearHeadless(cfg types.Config) error {
+// executeDryRunHeadless prints the plan for the operation as JSON on stdout.
+func executeDryRunHeadless(filenames []string, operation string, cfg types.Config, opts types.Options) error {
+	plan, err := helpers.PlanOperation(operation, filenames, cfg, opts)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func executeClearHeadless(cfg types.Config, opts types.Options) error {
+	index, err := helpers.LoadIndex(cfg)
+	if err != nil {
+		return fmt.Errorf("error loading index: %w", err)
+	}
+	if pinned := helpers.CountPinned(index); pinned > 0 {
+		if !confirmHeadless(fmt.Sprintf("Cache contains %d pinned item(s). Clear them too?", pinned)) {
+			return fmt.Errorf("refusing to clear %d pinned item(s); unpin them first with vx --unpin", pinned)
+		}
+	}
+
+	clearedItems := index.Items
+
 	fmt.Println("Clearing cache...")
 
 	cacheDir := helpers.ExpandPath(cfg.Cache.Directory)
@@ -49,49 +82,53 @@ func executeClearHeadless(cfg types.Config) error {
 	}
 
 	// Create empty index
-	index := types.Index{Items: []types.DeletedItem{}}
+	index = types.Index{Items: []types.DeletedItem{}}
 	if err := helpers.SaveIndex(index, cfg); err != nil {
 		return fmt.Errorf("failed to save index: %w", err)
 	}
 
 	// Log clear operation
-	if cfg.Logging.Enabled {
-		logDir := helpers.ExpandPath(cfg.Logging.Directory)
-		if err := os.MkdirAll(logDir, 0755); err == nil {
-			logPath := filepath.Join(logDir, "vanish.log")
-			if logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-				defer logFile.Close()
-				logFile.WriteString(fmt.Sprintf("%s CLEAR_ALL Cache cleared\n",
-					time.Now().Format("2006-01-02 15:04:05")))
-			}
-		}
+	if err := helpers.LogEvent("CLEAR_ALL", "Cache cleared", nil, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Warning: cache cleared but logging failed: %v\n", err)
 	}
 
 	fmt.Println("✓ Cache cleared successfully")
+	warnHookFailure(helpers.RunHook(helpers.HookPostClear, nil, clearedItems, cfg, opts))
 	return nil
 }
 
-func executePurgeHeadless(daysStr string, cfg types.Config) error {
-	days, err := strconv.Atoi(daysStr)
+func executePurgeHeadless(daysStr string, cfg types.Config, opts types.Options) error {
+	// Same check as the TUI and the --dry-run plan, so 0 cannot empty the cache
+	cutoff, err := helpers.PurgeCutoff(daysStr)
 	if err != nil {
-		return fmt.Errorf("invalid days value: %s", daysStr)
+		return err
 	}
 
-	fmt.Printf("Purging files older than %d days...\n", days)
-
-	cutoffDays := time.Duration(days) * 24 * time.Hour
-	cutoff := time.Now().Add(-cutoffDays)
+	fmt.Printf("Purging files older than %s days...\n", daysStr)
 
 	index, err := helpers.LoadIndex(cfg)
 	if err != nil {
 		return fmt.Errorf("error loading index: %w", err)
 	}
 
+	if helpers.HasHook(helpers.HookPrePurge, cfg, opts) {
+		var expired []types.DeletedItem
+		for _, item := range index.Items {
+			if item.IsExpired(cutoff) {
+				expired = append(expired, item)
+			}
+		}
+		if err := helpers.RunHook(helpers.HookPrePurge, nil, expired, cfg, opts); err != nil {
+			return fmt.Errorf("cancelled by hook: %w", err)
+		}
+	}
+
 	var remainingItems []types.DeletedItem
+	var purgedItems []types.DeletedItem
 	purgedCount := 0
 
 	for _, item := range index.Items {
-		if item.DeleteDate.Before(cutoff) {
+		if item.IsExpired(cutoff) {
 			// Remove the actual file or directory
 			if item.IsDirectory {
 				os.RemoveAll(item.CachePath)
@@ -99,11 +136,10 @@ func executePurgeHeadless(daysStr string, cfg types.Config) error {
 				os.Remove(item.CachePath)
 			}
 			purgedCount++
+			purgedItems = append(purgedItems, item)
 
 			// Log purge
-			if cfg.Logging.Enabled {
-				helpers.LogOperation("PURGE", item, cfg)
-			}
+			helpers.LogOperation("PURGE", item, nil, cfg)
 		} else {
 			remainingItems = append(remainingItems, item)
 		}
@@ -116,151 +152,177 @@ func executePurgeHeadless(daysStr string, cfg types.Config) error {
 	}
 
 	fmt.Printf("✓ Purged %d items\n", purgedCount)
+	warnHookFailure(helpers.RunHook(helpers.HookPostPurge, nil, purgedItems, cfg, opts))
+	return nil
+}
+
+func executeRestoreHeadless(patterns []string, cfg types.Config, opts types.Options) error {
+	items, err := helpers.FindRestoreItems(patterns, opts.Tags, cfg)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no cached items match the given patterns")
+	}
+
+	if err := helpers.RunHook(helpers.HookPreRestore, nil, items, cfg, opts); err != nil {
+		return fmt.Errorf("cancelled by hook: %w", err)
+	}
+
+	fmt.Printf("Restoring %d items...\n", len(items))
+	started := time.Now()
+
+	var restored []types.DeletedItem
+	for _, item := range items {
+		if err := helpers.RestoreFromCache(item, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Failed to restore %s: %v\n", item.OriginalPath, err)
+			continue
+		}
+		fmt.Printf("✓ Restored: %s\n", item.OriginalPath)
+		restored = append(restored, item)
+	}
+	restoredCount := len(restored)
+
+	fmt.Printf("✓ Successfully restored %d of %d items\n", restoredCount, len(items))
+	notifyHeadless("restore", restoredCount, len(items), started, cfg)
+	warnHookFailure(helpers.RunHook(helpers.HookPostRestore, nil, restored, cfg, opts))
+	if restoredCount < len(items) {
+		return fmt.Errorf("%d items could not be restored", len(items)-restoredCount)
+	}
 	return nil
 }
 
-func executeDeleteHeadless(filenames []string, cfg types.Config) error {
+func executeDeleteHeadless(filenames []string, cfg types.Config, opts types.Options) error {
 	// Ensure cache directory exists
 	cacheDir := helpers.ExpandPath(cfg.Cache.Directory)
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	// Check which files exist
+	// Check which files exist and are not protected
 	var validFiles []string
 	for _, filename := range filenames {
-		if _, err := os.Lstat(filename); err == nil {
-			validFiles = append(validFiles, filename)
-		} else {
+		if _, err := os.Lstat(filename); err != nil {
 			fmt.Fprintf(os.Stderr, "⚠ Skipping %s: does not exist\n", filename)
+			continue
+		}
+		protection := helpers.CheckProtected(filename, cfg)
+		if protection.IsRefused(opts.AllowProtected) {
+			hint := " (use --allow-protected to override)"
+			if protection.Hard {
+				hint = ""
+			}
+			fmt.Fprintf(os.Stderr, "⚠ Refusing to remove %s: %s%s\n", filename, protection.Reason, hint)
+			continue
+		}
+		if git := helpers.CheckGit(filename); git != nil {
+			if git.Error != "" {
+				fmt.Fprintf(os.Stderr, "⚠ %s is in git repo %s, %s\n", filename, git.Root, git.Error)
+			} else if len(git.Changes) > 0 {
+				// Nobody can type "yes" here, so it takes --allow-uncommitted
+				refused := !opts.AllowUncommitted
+				if refused {
+					fmt.Fprintf(os.Stderr, "⚠ Refusing to remove %s: it holds %d file(s) git has not committed (use --allow-uncommitted to override):\n", filename, len(git.Changes))
+				} else {
+					fmt.Fprintf(os.Stderr, "⚠ %s holds %d file(s) git has not committed:\n", filename, len(git.Changes))
+				}
+				for _, change := range git.Changes {
+					fmt.Fprintf(os.Stderr, "  %s (%s)\n", change.Path, change.State)
+				}
+				if refused {
+					continue
+				}
+			}
 		}
+		validFiles = append(validFiles, filename)
 	}
 
 	if len(validFiles) == 0 {
 		return fmt.Errorf("no valid files or directories found")
 	}
 
+	if err := helpers.RunHook(helpers.HookPreDelete, validFiles, nil, cfg, opts); err != nil {
+		return fmt.Errorf("cancelled by hook: %w", err)
+	}
+
 	fmt.Printf("Moving %d items to cache...\n", len(validFiles))
+	started := time.Now()
 
-	movedCount := 0
+	var moved []types.DeletedItem
 	for _, filename := range validFiles {
-		// Get file info using Lstat (doesn't follow symlinks)
-		stat, err := os.Lstat(filename)
+		item, err := helpers.MoveToCache(filename, cfg, opts)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "⚠ Skipping %s: %v\n", filename, err)
-			continue
-		}
-
-		// Get absolute path
-		absPath, err := filepath.Abs(filename)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "⚠ Skipping %s: failed to get absolute path: %v\n", filename, err)
-			continue
-		}
-
-		// Generate unique ID and cache filename
-		now := time.Now()
-		id := fmt.Sprintf("%d", now.UnixNano())
-		timestamp := now.Format("2006-01-02-15-04-05")
-		baseFilename := filepath.Base(filename)
-		cacheFilename := fmt.Sprintf("%s-%s-%s", id, timestamp, baseFilename)
-		cachePath := filepath.Join(cacheDir, cacheFilename)
-
-		// Determine file type
-		isSymlink := stat.Mode()&os.ModeSymlink != 0
-		isDir := stat.IsDir()
-		fileCount := 0
-		size := stat.Size()
-		linkTarget := ""
-
-		// Handle different file types
-		var moveErr error
-		if isSymlink {
-			linkTarget, err = os.Readlink(filename)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "⚠ Skipping %s: failed to read symlink: %v\n", filename, err)
+			if item.ID == "" {
+				fmt.Fprintf(os.Stderr, "⚠ Failed to move %s: %v\n", filename, err)
 				continue
 			}
-			moveErr = helpers.MoveSymlink(filename, cachePath)
-			size = stat.Size()
-		} else if isDir {
-			fileCount, _ = helpers.CountFilesInDirectory(filename)
-			size, _ = helpers.GetDirectorySize(filename)
-			moveErr = helpers.MoveDirectory(filename, cachePath)
-		} else {
-			moveErr = helpers.MoveFile(filename, cachePath)
-		}
-
-		if moveErr != nil {
-			fmt.Fprintf(os.Stderr, "⚠ Failed to move %s: %v\n", filename, moveErr)
-			continue
-		}
-
-		// Create deleted item with all metadata
-		item := types.DeletedItem{
-			ID:           id,
-			OriginalPath: absPath,
-			DeleteDate:   now,
-			CachePath:    cachePath,
-			IsDirectory:  isDir,
-			IsSymlink:    isSymlink,
-			LinkTarget:   linkTarget,
-			FileCount:    fileCount,
-			Size:         size,
-		}
-
-		// Update index
-		if err := helpers.AddToIndex(item, cfg); err != nil {
-			fmt.Fprintf(os.Stderr, "⚠ Warning: failed to update index for %s: %v\n", filename, err)
+			// The item reached the cache but the index could not be updated
+			fmt.Fprintf(os.Stderr, "⚠ Warning: %s: %v\n", filename, err)
 		}
 
-		// Log the operation
-		if cfg.Logging.Enabled {
-			helpers.LogOperation("DELETE", item, cfg)
+		fmt.Printf("✓ Moved to cache: %s\n", filename)
+		for _, kept := range item.KeptPaths {
+			fmt.Printf("  kept in place by .vanishignore: %s\n", filepath.Join(filename, kept))
 		}
-
-		fmt.Println("✓ Moved to cache: %s", filename)
-		movedCount++
+		moved = append(moved, item)
 	}
 
 	// Cleanup old files
 	fmt.Println("Cleaning up old files...")
-	cutoffDays := time.Duration(cfg.Cache.Days) * 24 * time.Hour
-	cutoff := time.Now().Add(-cutoffDays)
-
-	index, err := helpers.LoadIndex(cfg)
+	cleanedCount, err := helpers.CleanupExpired(cfg)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "⚠ Warning: failed to load index for cleanup: %v\n", err)
-	} else {
-		var remainingItems []types.DeletedItem
-		cleanedCount := 0
+		fmt.Fprintf(os.Stderr, "⚠ Warning: %v\n", err)
+	}
+	if cleanedCount > 0 {
+		fmt.Printf("✓ Cleaned up %d old items\n", cleanedCount)
+	}
 
-		for _, item := range index.Items {
-			if item.DeleteDate.Before(cutoff) {
-				if item.IsDirectory {
-					os.RemoveAll(item.CachePath)
-				} else {
-					os.Remove(item.CachePath)
-				}
-				cleanedCount++
+	fmt.Printf("✓ Successfully moved %d of %d items\n", len(moved), len(validFiles))
+	notifyHeadless("delete", len(moved), len(validFiles), started, cfg)
+	if opts.BatchID != "" {
+		fmt.Printf("Restore the whole batch with: vx restore %s\n", opts.BatchID)
+	}
+	warnHookFailure(helpers.RunHook(helpers.HookPostDelete, nil, moved, cfg, opts))
+	return nil
+}
 
-				if cfg.Logging.Enabled {
-					helpers.LogOperation("CLEANUP", item, cfg)
-				}
-			} else {
-				remainingItems = append(remainingItems, item)
-			}
-		}
+// notifyHeadless sends the desktop notification for a finished delete or
+// restore: failures always, success only after a long run.
+func notifyHeadless(operation string, done, total int, started time.Time, cfg types.Config) {
+	if done < total {
+		helpers.SendNotification(fmt.Sprintf("Vanish %s failed", operation),
+			fmt.Sprintf("%d of %d item(s) failed", total-done, total), true, cfg)
+		return
+	}
+	if !helpers.IsLongRunning(time.Since(started), cfg) {
+		return
+	}
+	message := fmt.Sprintf("Moved %d item(s) to the cache", done)
+	if operation == "restore" {
+		message = fmt.Sprintf("Restored %d item(s)", done)
+	}
+	helpers.SendNotification("Vanish", message, false, cfg)
+}
 
-		if cleanedCount > 0 {
-			index.Items = remainingItems
-			if err := helpers.SaveIndex(index, cfg); err != nil {
-				fmt.Fprintf(os.Stderr, "⚠ Warning: failed to update index: %v\n", err)
-			}
-			fmt.Printf("✓ Cleaned up %d old items\n", cleanedCount)
-		}
+// warnHookFailure reports a failed post hook. The operation itself is done,
+// so it is only a warning.
+func warnHookFailure(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Warning: %v\n", err)
 	}
+}
 
-	fmt.Printf("✓ Successfully moved %d of %d items\n", movedCount, len(validFiles))
-	return nil
+// confirmHeadless asks a yes/no question on the terminal. When stdin is not
+// a terminal there is nobody to answer, so the question is treated as "no".
+func confirmHeadless(question string) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
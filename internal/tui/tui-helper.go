@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"vanish/internal/helpers"
 	"vanish/internal/types"
 )
 
@@ -57,7 +58,12 @@ func (m *Model) renderDeleteConfirmation(content *strings.Builder, contentWidth
 	content.WriteString("\n")
 
 	validCount, invalidCount, totalFileCount := m.analyzeFileInfos()
-	listContent := m.buildFileInfosList(validCount, invalidCount, &totalFileCount)
+	var listContent string
+	if m.Options.BatchID != "" {
+		listContent = m.buildGroupedFileList()
+	} else {
+		listContent = m.buildFileInfosList(validCount, invalidCount, &totalFileCount)
+	}
 
 	content.WriteString(m.Styles.List.Render(listContent))
 
@@ -65,6 +71,16 @@ func (m *Model) renderDeleteConfirmation(content *strings.Builder, contentWidth
 		m.renderInvalidFilesWarning(content, invalidCount)
 	}
 
+	if protectedCount := countProtected(m.FileInfos); protectedCount > 0 {
+		m.renderProtectedFilesWarning(content, protectedCount)
+	}
+
+	if changed := countGitChanges(m.FileInfos); changed > 0 {
+		content.WriteString("\n")
+		content.WriteString(m.Styles.Warning.Render(fmt.Sprintf(
+			"⚠ Warning: %d file(s) have changes that are not committed to git", changed)))
+	}
+
 	if validCount > 0 {
 		m.renderDeleteSummary(content, validCount, totalFileCount, contentWidth)
 	}
@@ -72,6 +88,9 @@ func (m *Model) renderDeleteConfirmation(content *strings.Builder, contentWidth
 
 func (m *Model) analyzeFileInfos() (validCount, invalidCount, totalFileCount int) {
 	for _, info := range m.FileInfos {
+		if info.Protected {
+			continue // counted separately by countProtected
+		}
 		if info.Exists {
 			validCount++
 			if info.IsDirectory {
@@ -90,7 +109,9 @@ func (m *Model) buildFileInfosList(_, _ int, totalFileCount *int) string {
 	var listContent strings.Builder
 
 	for _, info := range m.FileInfos {
-		if info.Exists {
+		if info.Protected {
+			m.appendProtectedFileInfo(&listContent, info)
+		} else if info.Exists {
 			m.appendValidFileInfo(&listContent, info, totalFileCount)
 		} else {
 			m.appendInvalidFileInfo(&listContent, info)
@@ -100,6 +121,67 @@ func (m *Model) buildFileInfosList(_, _ int, totalFileCount *int) string {
 	return listContent.String()
 }
 
+// Limits for the grouped list of a --match batch, which can be huge
+const (
+	maxGroupsShown  = 20
+	maxEntriesShown = 10
+)
+
+// buildGroupedFileList lists the paths of a --match batch under their parent
+// directory, with the number of items and their size per directory.
+func (m *Model) buildGroupedFileList() string {
+	var listContent strings.Builder
+
+	var dirs []string
+	groups := make(map[string][]types.FileInfo)
+	for _, info := range m.FileInfos {
+		dir := filepath.Dir(info.Path)
+		if _, ok := groups[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		groups[dir] = append(groups[dir], info)
+	}
+
+	inlineInfoStyle := m.Styles.Info.Border(lipgloss.Border{}).Padding(0)
+	for i, dir := range dirs {
+		if i == maxGroupsShown {
+			listContent.WriteString(inlineInfoStyle.Render(fmt.Sprintf("… and %d more directories", len(dirs)-i)))
+			listContent.WriteString("\n")
+			break
+		}
+
+		var size int64
+		for _, info := range groups[dir] {
+			if info.Exists && !info.Protected {
+				size += info.Size
+			}
+		}
+		listContent.WriteString(m.getFileIcon(true))
+		listContent.WriteString(m.Styles.Filename.Render(dir + "/"))
+		listContent.WriteString(inlineInfoStyle.Render(fmt.Sprintf(" (%d items, %s)", len(groups[dir]), helpers.FormatBytes(size))))
+		listContent.WriteString("\n")
+
+		for j, info := range groups[dir] {
+			listContent.WriteString("    ")
+			if j == maxEntriesShown {
+				listContent.WriteString(inlineInfoStyle.Render(fmt.Sprintf("… and %d more", len(groups[dir])-j)))
+				listContent.WriteString("\n")
+				break
+			}
+			info.Path = filepath.Base(info.Path)
+			if info.Protected {
+				m.appendProtectedFileInfo(&listContent, info)
+			} else if info.Exists {
+				m.appendValidFileInfo(&listContent, info, nil)
+			} else {
+				m.appendInvalidFileInfo(&listContent, info)
+			}
+		}
+	}
+
+	return listContent.String()
+}
+
 func (m *Model) appendValidFileInfo(listContent *strings.Builder, info types.FileInfo, _ *int) {
 	icon := m.getFileIcon(info.IsDirectory)
 	listContent.WriteString(icon)
@@ -112,9 +194,92 @@ func (m *Model) appendValidFileInfo(listContent *strings.Builder, info types.Fil
 			listContent.WriteString(inlineInfoStyle.Render(" (empty)"))
 		}
 	}
+	if m.Options.BatchID != "" {
+		// Selected by --match or a filter, show why it was picked
+		inlineInfoStyle := m.Styles.Info.Border(lipgloss.Border{}).Padding(0)
+		listContent.WriteString(inlineInfoStyle.Render(fmt.Sprintf(" · %s, modified %s ago",
+			helpers.FormatBytes(info.Size), formatAge(time.Since(info.ModTime)))))
+	}
+	if info.ProtectedReason != "" {
+		// Protected but explicitly overridden with --allow-protected
+		listContent.WriteString(m.Styles.Warning.Render(fmt.Sprintf(" (protected: %s, overridden)", info.ProtectedReason)))
+	}
+	listContent.WriteString("\n")
+	if len(info.Kept) > 0 {
+		listContent.WriteString(m.Styles.Warning.Render("    ↳ keeping " + summarizeKept(info.Kept) + " (.vanishignore)"))
+		listContent.WriteString("\n")
+	}
+	if info.Git != nil {
+		m.appendGitChanges(listContent, info.Git)
+	}
+}
+
+// Limit for the uncommitted files listed under a single path
+const maxGitChangesShown = 5
+
+// appendGitChanges lists the uncommitted files below a path about to be
+// deleted from a git working tree.
+func (m *Model) appendGitChanges(listContent *strings.Builder, git *types.GitStatus) {
+	if git.Error != "" {
+		listContent.WriteString(m.Styles.Warning.Render(fmt.Sprintf("    ↳ in git repo %s, %s", git.Root, git.Error)))
+		listContent.WriteString("\n")
+		return
+	}
+	for i, change := range git.Changes {
+		if i == maxGitChangesShown {
+			listContent.WriteString(m.Styles.Warning.Render(fmt.Sprintf("    ↳ … and %d more uncommitted", len(git.Changes)-i)))
+			listContent.WriteString("\n")
+			break
+		}
+		listContent.WriteString(m.Styles.Warning.Render(fmt.Sprintf("    ↳ %s (%s)", change.Path, change.State)))
+		listContent.WriteString("\n")
+	}
+}
+
+// countGitChanges returns how many uncommitted git files the deletable paths
+// would take with them.
+func countGitChanges(fileInfos []types.FileInfo) int {
+	count := 0
+	for _, info := range fileInfos {
+		if info.Exists && !info.Protected && info.Git != nil {
+			count += len(info.Git.Changes)
+		}
+	}
+	return count
+}
+
+// summarizeKept lists the first few kept paths and counts the rest.
+func summarizeKept(kept []string) string {
+	const shown = 3
+	if len(kept) <= shown {
+		return strings.Join(kept, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(kept[:shown], ", "), len(kept)-shown)
+}
+
+func (m *Model) appendProtectedFileInfo(listContent *strings.Builder, info types.FileInfo) {
+	icon := "NO:"
+	if m.Config.UI.Progress.ShowEmoji {
+		icon = "🛡"
+	}
+
+	listContent.WriteString(fmt.Sprintf("%-4s", icon))
+	listContent.WriteString(m.Styles.StatusBad.Render(info.Path))
+	listContent.WriteString(m.Styles.Warning.Render(fmt.Sprintf(" (refused: %s)", info.ProtectedReason)))
 	listContent.WriteString("\n")
 }
 
+// countProtected returns how many of the given paths were refused as protected.
+func countProtected(fileInfos []types.FileInfo) int {
+	count := 0
+	for _, info := range fileInfos {
+		if info.Protected {
+			count++
+		}
+	}
+	return count
+}
+
 func (m *Model) appendInvalidFileInfo(listContent *strings.Builder, info types.FileInfo) {
 	// Use consistent spacing/width with valid files
 	icon := "ERR:"
@@ -163,6 +328,12 @@ func (m *Model) renderInvalidFilesWarning(content *strings.Builder, invalidCount
 	content.WriteString(warningStyle.Render(warningText))
 }
 
+func (m *Model) renderProtectedFilesWarning(content *strings.Builder, protectedCount int) {
+	content.WriteString("\n")
+	warningText := fmt.Sprintf("⚠ Warning: %d protected path(s) refused (use --allow-protected to override)", protectedCount)
+	content.WriteString(m.Styles.Warning.Render(warningText))
+}
+
 func (m *Model) renderDeleteSummary(content *strings.Builder, validCount, totalFileCount, contentWidth int) {
 	content.WriteString("\n")
 
@@ -170,12 +341,36 @@ func (m *Model) renderDeleteSummary(content *strings.Builder, validCount, totalF
 	if totalFileCount > validCount {
 		infoText += fmt.Sprintf(" | Files affected: %d", totalFileCount)
 	}
+	infoText += fmt.Sprintf(" | Size: %s", helpers.FormatBytes(m.totalValidSize()))
 	infoText += fmt.Sprintf(" | Recoverable for %d days", m.Config.Cache.Days)
 
 	infoStyle := m.Styles.Info.MaxWidth(contentWidth).Align(lipgloss.Left)
 	content.WriteString(infoStyle.Render(infoText))
 }
 
+// formatAge renders a duration in its largest sensible unit, e.g. 45d or 3h.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// totalValidSize returns the size of everything that will be moved.
+func (m *Model) totalValidSize() int64 {
+	var size int64
+	for _, info := range m.FileInfos {
+		if info.Exists && !info.Protected {
+			size += info.Size
+		}
+	}
+	return size
+}
+
 func (m *Model) renderMovingState(content *strings.Builder, contentWidth int) {
 	statusText := m.buildProgressStatusText("Moving", "📦")
 	m.renderProgressState(content, statusText, contentWidth)
@@ -250,6 +445,10 @@ func (m *Model) renderPurgingState(content *strings.Builder) {
 	m.renderSimpleProgressState(content, "🔥", "Purging old cached files...")
 }
 
+func (m *Model) renderHookingState(content *strings.Builder) {
+	m.renderSimpleProgressState(content, "🪝", "Running hook...")
+}
+
 func (m *Model) renderSimpleProgressState(content *strings.Builder, emoji, message string) {
 	if m.Config.UI.Progress.ShowEmoji {
 		content.WriteString(emoji + " ")
@@ -263,6 +462,11 @@ func (m *Model) renderDoneState(content *strings.Builder, contentWidth int) {
 	content.WriteString(m.Styles.Success.Render(successMsg))
 	content.WriteString("\n")
 
+	for _, warning := range m.Warnings {
+		content.WriteString(m.Styles.Warning.Render("⚠ Warning: " + warning))
+		content.WriteString("\n")
+	}
+
 	if m.shouldShowItemDetails() {
 		m.renderItemDetails(content, contentWidth)
 	}
@@ -297,6 +501,9 @@ func (m *Model) buildSuccessMessage() string {
 		successMsg = fmt.Sprintf("%sSuccessfully restored %d item(s)!", emoji, len(m.ProcessedItems))
 	default:
 		successMsg = fmt.Sprintf("%sSuccessfully processed %d item(s)!", emoji, len(m.ProcessedItems))
+		if m.Options.BatchID != "" {
+			successMsg += fmt.Sprintf("\nRestore the whole batch with: vx restore %s", m.Options.BatchID)
+		}
 	}
 
 	return successMsg
@@ -366,3 +573,66 @@ func (m *Model) renderErrorState(content *strings.Builder) {
 	content.WriteString("\n")
 	content.WriteString(m.Styles.Help.Render("Press Enter or 'q' to exit"))
 }
+
+func (m *Model) renderPreviewState(content *strings.Builder, contentWidth int) {
+	title := "DRY RUN - nothing will be changed"
+	if m.Config.UI.Progress.ShowEmoji {
+		title = "🧪 " + title
+	}
+	content.WriteString(m.Styles.Title.Render(title))
+	content.WriteString("\n\n")
+
+	if len(m.Plan.Actions) == 0 {
+		content.WriteString(m.Styles.Info.Render(fmt.Sprintf("Nothing to %s", m.Plan.Operation)))
+		content.WriteString("\n")
+	} else {
+		content.WriteString(m.Styles.Question.Render(fmt.Sprintf("%s would:", m.Plan.Operation)))
+		content.WriteString("\n")
+		for _, action := range m.Plan.Actions {
+			content.WriteString(m.renderPlanAction(action))
+		}
+	}
+
+	if len(m.Plan.Expiring) > 0 {
+		content.WriteString("\n")
+		content.WriteString(m.Styles.Question.Render("Auto-cleanup afterwards would:"))
+		content.WriteString("\n")
+		for _, action := range m.Plan.Expiring {
+			content.WriteString(m.renderPlanAction(action))
+		}
+	}
+
+	content.WriteString("\n")
+	infoStyle := m.Styles.Info.MaxWidth(contentWidth).Align(lipgloss.Left)
+	content.WriteString(infoStyle.Render(fmt.Sprintf("Total size affected: %s", helpers.FormatBytes(m.Plan.TotalBytes))))
+	content.WriteString("\n")
+	content.WriteString(m.Styles.Help.Render("Run again without --dry-run to apply. Press Enter or 'q' to exit"))
+}
+
+func (m *Model) renderPlanAction(action types.PlanAction) string {
+	var line strings.Builder
+	line.WriteString(fmt.Sprintf("  %-8s ", action.Action))
+
+	switch action.Action {
+	case "refuse", "skip":
+		line.WriteString(m.Styles.StatusBad.Render(action.Path))
+	default:
+		line.WriteString(m.Styles.Filename.Render(action.Path))
+	}
+
+	if action.Target != "" && (action.Action == "move" || action.Action == "restore") {
+		line.WriteString(" -> ")
+		line.WriteString(action.Target)
+	}
+	if action.Size > 0 {
+		line.WriteString(fmt.Sprintf(" (%s)", helpers.FormatBytes(action.Size)))
+	}
+	if action.Reason != "" {
+		line.WriteString(m.Styles.Warning.Render(fmt.Sprintf(" [%s]", action.Reason)))
+	}
+	if len(action.Kept) > 0 {
+		line.WriteString(m.Styles.Warning.Render(" [keeping " + summarizeKept(action.Kept) + "]"))
+	}
+	line.WriteString("\n")
+	return line.String()
+}
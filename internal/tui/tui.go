@@ -6,8 +6,6 @@ package tui
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -38,19 +36,28 @@ type Model struct {
 	NoConfirm      bool
 	Operation      string // "delete", "restore", "clear", "purge"
 	RestoreItems   []types.DeletedItem
+	PinnedCount    int // pinned items that a clear would remove
+	Options        types.Options
+	Plan           types.Plan          // what a --dry-run would do
+	ConfirmInput   string              // typed confirmation for uncommitted git changes
+	ClearItems     []types.DeletedItem // what a clear removes, for the post_clear hook
+	Warnings       []string            // partial failures and post hook failures, shown with the result
+	StartedAt      time.Time           // when the confirmed operation started, zero before
 }
 
 // InitialModel initializes and returns a new Model with configuration, progress, styles, and file info prepared.
-func InitialModel(filenames []string, operation string, noConfirm bool) (*Model, error) {
+func InitialModel(filenames []string, operation string, opts types.Options) (*Model, error) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return nil, err
 	}
+	cfg = helpers.WithVerbosity(cfg, opts.Verbosity)
 
 	prog := helpers.SetUpProgress(cfg)
 	styles := helpers.CreateThemeStyles(cfg)
 
 	// Check if no_confirm is set in config and not overridden by flag
+	noConfirm := opts.NoConfirm
 	if cfg.Cache.NoConfirm && !noConfirm {
 		noConfirm = true
 	}
@@ -66,21 +73,38 @@ func InitialModel(filenames []string, operation string, noConfirm bool) (*Model,
 		ProcessedItems: make([]types.DeletedItem, 0),
 		TotalFiles:     len(filenames),
 		NoConfirm:      noConfirm,
+		Options:        opts,
 	}, nil
 }
 
 // Init initializes the TUI model and triggers the initial
 // command based on the selected operation.
 func (m *Model) Init() tea.Cmd {
+	if m.Options.DryRun {
+		m.State = "checking"
+		return tea.Batch(
+			m.Progress.SetPercent(0.1),
+			helpers.PlanOperationCmd(m.Operation, m.Filenames, m.Config, m.Options),
+		)
+	}
+
 	switch m.Operation {
 	case "clear":
-		m.State = "clearing"
+		m.State = "checking"
 		return tea.Batch(
 			m.Progress.SetPercent(0.1),
-			helpers.ClearAllCache(m.Config),
+			checkPinnedBeforeClear(m.Config),
 		)
 	case "purge":
+		if helpers.HasHook(helpers.HookPrePurge, m.Config, m.Options) {
+			m.State = "hooking"
+			return tea.Batch(
+				m.Progress.SetPercent(0.1),
+				prePurgeHook(m.Filenames[0], m.Config, m.Options),
+			)
+		}
 		m.State = "purging"
+		m.StartedAt = time.Now()
 		return tea.Batch(
 			m.Progress.SetPercent(0.1),
 			helpers.PurgeOldFiles(m.Config, m.Filenames[0]),
@@ -88,12 +112,12 @@ func (m *Model) Init() tea.Cmd {
 	case "restore":
 		m.State = "checking"
 		return tea.Batch(
-			helpers.CheckRestoreItems(m.Filenames, m.Config),
+			helpers.CheckRestoreItems(m.Filenames, m.Options.Tags, m.Config),
 			m.Progress.SetPercent(0.1),
 		)
 	default: // delete
 		return tea.Batch(
-			helpers.CheckFilesExist(m.Filenames),
+			helpers.CheckFilesExist(m.Filenames, m.Config, m.Options),
 			m.Progress.SetPercent(0.1),
 		)
 	}
@@ -105,56 +129,47 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.State == "confirming" && m.needsTypedConfirm() {
+			return m.updateTypedConfirm(msg)
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 		case "y", "Y":
 			if m.State == "confirming" {
-				m.Confirmed = true
-				if m.Operation == "restore" {
-					m.State = "restoring"
-				} else {
-					m.State = "moving"
-				}
-				m.CurrentIndex = 0
-				return m, tea.Batch(
-					m.Progress.SetPercent(0.3),
-					processNextItem(m),
-				)
+				return m.startConfirmed()
 			}
 		case "n", "N":
 			if m.State == "confirming" {
 				return m, tea.Quit
 			}
 		case "enter":
-			if m.State == "done" || m.State == "error" {
+			if m.State == "done" || m.State == "error" || m.State == "preview" {
 				return m, tea.Quit
 			}
 		}
 
+	case types.PlanMsg:
+		m.Plan = msg.Plan
+		m.State = "preview"
+		return m, m.Progress.SetPercent(1.0)
+
 	case types.FilesExistMsg:
 		m.FileInfos = msg.FileInfos
-		validFiles := 0
-		for _, info := range m.FileInfos {
-			if info.Exists {
-				validFiles++
-			}
-		}
+		validFiles := helpers.CountValidFiles(m.FileInfos)
 
 		if validFiles == 0 {
 			m.State = "error"
 			m.ErrorMsg = "No valid files or directories found"
+			if protected := countProtected(m.FileInfos); protected > 0 {
+				m.ErrorMsg += fmt.Sprintf(" (%d protected path(s) refused, use --allow-protected to override)", protected)
+			}
 			return m, nil
 		}
 
-		if m.NoConfirm {
-			m.Confirmed = true
-			m.State = "moving"
-			m.CurrentIndex = 0
-			return m, tea.Batch(
-				m.Progress.SetPercent(0.3),
-				processNextItem(m),
-			)
+		// Uncommitted git changes need "yes" typed out, even with --noconfirm
+		if m.NoConfirm && !m.needsTypedConfirm() {
+			return m.startConfirmed()
 		}
 		m.State = "confirming"
 		return m, m.Progress.SetPercent(0.2)
@@ -168,22 +183,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if m.NoConfirm {
-			m.Confirmed = true
-			m.State = "restoring"
-			m.CurrentIndex = 0
-			return m, tea.Batch(
-				m.Progress.SetPercent(0.3),
-				processNextItem(m),
-			)
+			return m.startConfirmed()
 		}
 		m.State = "confirming"
 		return m, m.Progress.SetPercent(0.2)
 
 	case types.FileMoveMsg:
+		if msg.Err != nil && msg.Item.ID == "" {
+			return m.fail(fmt.Sprintf("Error processing item: %v", msg.Err))
+		}
 		if msg.Err != nil {
-			m.State = "error"
-			m.ErrorMsg = fmt.Sprintf("Error processing item: %v", msg.Err)
-			return m, nil
+			// The item reached the cache but the index could not be updated
+			m.Warnings = append(m.Warnings, fmt.Sprintf("%s: %v", msg.Item.OriginalPath, msg.Err))
 		}
 
 		if msg.Item.ID != "" {
@@ -216,9 +227,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case types.RestoreMsg:
 		if msg.Err != nil {
-			m.State = "error"
-			m.ErrorMsg = fmt.Sprintf("Error restoring item: %v", msg.Err)
-			return m, nil
+			return m.fail(fmt.Sprintf("Error restoring item: %v", msg.Err))
 		}
 
 		if msg.Item.ID != "" {
@@ -240,31 +249,47 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// All items restored
-		m.State = "done"
-		return m, m.Progress.SetPercent(1.0)
+		return m.finish(helpers.HookPostRestore, m.ProcessedItems)
 
 	case types.CleanupMsg:
-		m.State = "done"
-		return m, m.Progress.SetPercent(1.0)
+		return m.finish(helpers.HookPostDelete, m.ProcessedItems)
+
+	case types.ClearCheckMsg:
+		m.ClearItems = msg.Items
+		// Pinned items always need an explicit confirmation, even with --noconfirm
+		if msg.PinnedCount > 0 {
+			m.PinnedCount = msg.PinnedCount
+			m.State = "confirming"
+			return m, m.Progress.SetPercent(0.2)
+		}
+		return m.startConfirmed()
 
 	case types.ClearMsg:
 		if msg.Err != nil {
-			m.State = "error"
-			m.ErrorMsg = fmt.Sprintf("Error clearing cache: %v", msg.Err)
-			return m, nil
+			return m.fail(fmt.Sprintf("Error clearing cache: %v", msg.Err))
 		}
-		m.State = "done"
-		return m, m.Progress.SetPercent(1.0)
+		return m.finish(helpers.HookPostClear, m.ClearItems)
 
 	case types.PurgeMsg:
 		if msg.Err != nil {
-			m.State = "error"
-			m.ErrorMsg = fmt.Sprintf("Error purging cache: %v", msg.Err)
-			return m, nil
+			return m.fail(fmt.Sprintf("Error purging cache: %v", msg.Err))
 		}
 		m.ProcessedFiles = msg.PurgedCount
-		m.State = "done"
-		return m, m.Progress.SetPercent(1.0)
+		return m.finish(helpers.HookPostPurge, msg.Items)
+
+	case types.HookMsg:
+		if helpers.IsPreHook(msg.Event) {
+			if msg.Err != nil {
+				m.State = "error"
+				m.ErrorMsg = fmt.Sprintf("Cancelled by hook: %v", msg.Err)
+				return m, nil
+			}
+			return m.startOperation()
+		}
+		if msg.Err != nil {
+			m.Warnings = append(m.Warnings, msg.Err.Error())
+		}
+		return m.done()
 
 	case progress.FrameMsg:
 		progressModel, cmd := m.Progress.Update(msg)
@@ -272,9 +297,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case types.ErrorMsg:
-		m.State = "error"
-		m.ErrorMsg = string(msg)
-		return m, nil
+		return m.fail(string(msg))
 	}
 
 	return m, tea.Batch(cmds...)
@@ -311,15 +334,161 @@ func (m *Model) View() string {
 		m.renderClearingState(&content)
 	case "purging":
 		m.renderPurgingState(&content)
+	case "hooking":
+		m.renderHookingState(&content)
 	case "done":
 		m.renderDoneState(&content, contentWidth)
 	case "error":
 		m.renderErrorState(&content)
+	case "preview":
+		m.renderPreviewState(&content, contentWidth)
 	}
 
 	return m.Styles.Root.Render(content.String())
 }
 
+// startConfirmed runs the pre hook of a confirmed operation, if any, and
+// otherwise starts the operation right away.
+func (m *Model) startConfirmed() (tea.Model, tea.Cmd) {
+	m.Confirmed = true
+
+	var event string
+	var paths []string
+	var items []types.DeletedItem
+	switch m.Operation {
+	case "delete":
+		event = helpers.HookPreDelete
+		paths = []string{}
+		for _, info := range m.FileInfos {
+			if info.Exists && !info.Protected {
+				paths = append(paths, info.Path)
+			}
+		}
+	case "restore":
+		event, items = helpers.HookPreRestore, m.RestoreItems
+	}
+
+	if event != "" && helpers.HasHook(event, m.Config, m.Options) {
+		m.State = "hooking"
+		return m, tea.Batch(
+			m.Progress.SetPercent(0.25),
+			helpers.RunHookCmd(event, paths, items, m.Config, m.Options),
+		)
+	}
+	return m.startOperation()
+}
+
+// startOperation starts the confirmed operation once its pre hook passed.
+func (m *Model) startOperation() (tea.Model, tea.Cmd) {
+	m.StartedAt = time.Now()
+	if m.Operation == "purge" {
+		m.State = "purging"
+		return m, tea.Batch(
+			m.Progress.SetPercent(0.3),
+			helpers.PurgeOldFiles(m.Config, m.Filenames[0]),
+		)
+	}
+	if m.Operation == "clear" {
+		m.State = "clearing"
+		return m, tea.Batch(
+			m.Progress.SetPercent(0.3),
+			helpers.ClearAllCache(m.Config),
+		)
+	}
+	m.CurrentIndex = 0
+	if m.Operation == "restore" {
+		m.State = "restoring"
+	} else {
+		m.State = "moving"
+		m.CurrentIndex = helpers.FindNextValidFile(m.FileInfos, 0)
+	}
+	return m, tea.Batch(
+		m.Progress.SetPercent(0.3),
+		processNextItem(m),
+	)
+}
+
+// needsTypedConfirm reports whether the delete would take uncommitted git
+// changes with it, in which case a single 'y' is not enough unless
+// --allow-uncommitted was given.
+func (m *Model) needsTypedConfirm() bool {
+	return m.Operation == "delete" && !m.Options.AllowUncommitted && countGitChanges(m.FileInfos) > 0
+}
+
+// updateTypedConfirm collects the "yes" the user has to type to delete
+// uncommitted git changes.
+func (m *Model) updateTypedConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		if strings.EqualFold(strings.TrimSpace(m.ConfirmInput), "yes") {
+			return m.startConfirmed()
+		}
+		m.ConfirmInput = ""
+	case tea.KeyBackspace:
+		if runes := []rune(m.ConfirmInput); len(runes) > 0 {
+			m.ConfirmInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes:
+		if msg.String() == "n" || msg.String() == "N" || msg.String() == "q" {
+			if m.ConfirmInput == "" {
+				return m, tea.Quit
+			}
+		}
+		m.ConfirmInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// finish runs the post hook of the operation, if any, before showing the
+// result. A failing post hook only adds a warning.
+func (m *Model) finish(event string, items []types.DeletedItem) (tea.Model, tea.Cmd) {
+	if helpers.HasHook(event, m.Config, m.Options) {
+		m.State = "hooking"
+		return m, tea.Batch(
+			m.Progress.SetPercent(0.9),
+			helpers.RunHookCmd(event, nil, items, m.Config, m.Options),
+		)
+	}
+	return m.done()
+}
+
+// done shows the result, with a desktop notification if a delete or
+// restore ran long enough for the user to have looked away.
+func (m *Model) done() (tea.Model, tea.Cmd) {
+	m.State = "done"
+	cmds := []tea.Cmd{m.Progress.SetPercent(1.0)}
+
+	if (m.Operation == "delete" || m.Operation == "restore") && helpers.IsLongRunning(time.Since(m.StartedAt), m.Config) {
+		message := fmt.Sprintf("Moved %d item(s) to the cache", len(m.ProcessedItems))
+		if m.Operation == "restore" {
+			message = fmt.Sprintf("Restored %d item(s)", len(m.ProcessedItems))
+		}
+		cmds = append(cmds, sendNotification("Vanish", message, false, m.Config))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// fail shows an error. Failures of a running operation are also sent as a
+// desktop notification.
+func (m *Model) fail(errMsg string) (tea.Model, tea.Cmd) {
+	m.State = "error"
+	m.ErrorMsg = errMsg
+	if m.StartedAt.IsZero() {
+		return m, nil
+	}
+	return m, sendNotification(fmt.Sprintf("Vanish %s failed", m.Operation), errMsg, true, m.Config)
+}
+
+// sendNotification sends a desktop notification in the background.
+func sendNotification(title, message string, isError bool, config types.Config) tea.Cmd {
+	return func() tea.Msg {
+		helpers.SendNotification(title, message, isError, config)
+		return nil
+	}
+}
+
 func processNextItem(m *Model) tea.Cmd {
 	if m.Operation == "restore" {
 		if m.CurrentIndex >= len(m.RestoreItems) {
@@ -331,207 +500,61 @@ func processNextItem(m *Model) tea.Cmd {
 	if m.CurrentIndex < 0 || m.CurrentIndex >= len(m.FileInfos) {
 		return nil
 	}
-	// Make sure the file at current index exists
-	if !m.FileInfos[m.CurrentIndex].Exists {
+	// Make sure the file at current index exists and is not protected
+	if info := m.FileInfos[m.CurrentIndex]; !info.Exists || info.Protected {
 		return nil
 	}
-	return moveFileToCache(m.FileInfos[m.CurrentIndex].Path, m.Config)
+	return moveFileToCache(m.FileInfos[m.CurrentIndex].Path, m.Config, m.Options)
 }
 
 // restoreFromCache restores a deleted item from cache back to its original location
 func restoreFromCache(item types.DeletedItem, config types.Config) tea.Cmd {
 	return func() tea.Msg {
-		// Check if cache file exists
-		if _, err := os.Lstat(item.CachePath); os.IsNotExist(err) {
-			return types.RestoreMsg{Err: fmt.Errorf("cached file not found: %s", item.CachePath)}
+		if err := helpers.RestoreFromCache(item, config); err != nil {
+			return types.RestoreMsg{Err: err}
 		}
-
-		// Create directory for original path if needed
-		originalDir := filepath.Dir(item.OriginalPath)
-		if err := os.MkdirAll(originalDir, 0755); err != nil {
-			return types.RestoreMsg{Err: fmt.Errorf("failed to create directory %s: %v", originalDir, err)}
-		}
-
-		// Check if original path already exists
-		if _, err := os.Lstat(item.OriginalPath); !os.IsNotExist(err) {
-			return types.RestoreMsg{Err: fmt.Errorf("destination already exists: %s", item.OriginalPath)}
-		}
-
-		// Restore based on item type
-		var err error
-		if item.IsSymlink {
-			// Restore symlink
-			err = helpers.RestoreSymlink(item.CachePath, item.OriginalPath)
-		} else if item.IsDirectory {
-			// Restore directory
-			err = helpers.MoveDirectory(item.CachePath, item.OriginalPath)
-		} else {
-			// Restore regular file
-			err = helpers.MoveFile(item.CachePath, item.OriginalPath)
-		}
-
-		if err != nil {
-			return types.RestoreMsg{Err: fmt.Errorf("failed to restore %s: %v", item.ItemType(), err)}
-		}
-
-		// Remove from index
-		if err := helpers.RemoveFromIndex(item.ID, config); err != nil {
-			// Log error but don't fail the restore
-			if config.Logging.Enabled {
-				logDir := helpers.ExpandPath(config.Logging.Directory)
-				if err := os.MkdirAll(logDir, 0755); err == nil {
-					logPath := filepath.Join(logDir, "vanish.log")
-					if logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
-						defer logFile.Close()
-
-						if _, err := logFile.WriteString(fmt.Sprintf("%s ERROR Failed to remove from index: %s\n",
-							time.Now().Format("2006-01-02 15:04:05"), item.ID)); err != nil {
-							fmt.Fprintf(os.Stderr, "Failed to write to log file: %v\n", err)
-						}
-					}
-
-				}
-			}
-		}
-
-		// Log the restore operation
-		if config.Logging.Enabled {
-			helpers.LogOperation("RESTORE", item, config)
-		}
-
 		return types.RestoreMsg{Item: item, Err: nil}
 	}
 }
 
 // moveFileToCache moves a file, directory, or symlink to the cache
-func moveFileToCache(filename string, config types.Config) tea.Cmd {
+func moveFileToCache(filename string, config types.Config, opts types.Options) tea.Cmd {
 	return func() tea.Msg {
-		// Ensure cache directory exists
-		cacheDir := helpers.ExpandPath(config.Cache.Directory)
-		if err := os.MkdirAll(cacheDir, 0755); err != nil {
-			return types.FileMoveMsg{Err: err}
-		}
-
-		// Get file info using Lstat (doesn't follow symlinks)
-		stat, err := os.Lstat(filename)
-		if err != nil {
-			return types.FileMoveMsg{Err: err}
-		}
-
-		// Get absolute path
-		absPath, err := filepath.Abs(filename)
-		if err != nil {
-			return types.FileMoveMsg{Err: err}
-		}
-
-		// Generate unique ID and cache filename
-		now := time.Now()
-		id := fmt.Sprintf("%d", now.UnixNano())
-		timestamp := now.Format("2006-01-02-15-04-05")
-		baseFilename := filepath.Base(filename)
-		cacheFilename := fmt.Sprintf("%s-%s-%s", id, timestamp, baseFilename)
-		cachePath := filepath.Join(cacheDir, cacheFilename)
-
-		// Determine file type
-		isSymlink := stat.Mode()&os.ModeSymlink != 0
-		isDir := stat.IsDir()
-		fileCount := 0
-		size := stat.Size()
-		linkTarget := ""
-
-		// Handle different file types
-		if isSymlink {
-			// Handle symbolic link
-			linkTarget, err = os.Readlink(filename)
-			if err != nil {
-				return types.FileMoveMsg{Err: fmt.Errorf("failed to read symlink: %v", err)}
-			}
-
-			if err := helpers.MoveSymlink(filename, cachePath); err != nil {
-				return types.FileMoveMsg{Err: fmt.Errorf("failed to move symlink: %v", err)}
-			}
-
-			// For symlinks, size is typically small (just the link itself)
-			size = stat.Size()
-
-		} else if isDir {
-			// Handle directory
-			fileCount, _ = helpers.CountFilesInDirectory(filename)
-			size, _ = helpers.GetDirectorySize(filename)
-
-			if err := helpers.MoveDirectory(filename, cachePath); err != nil {
-				return types.FileMoveMsg{Err: fmt.Errorf("failed to move directory: %v", err)}
-			}
-
-		} else {
-			// Handle regular file
-			if err := helpers.MoveFile(filename, cachePath); err != nil {
-				return types.FileMoveMsg{Err: fmt.Errorf("failed to move file: %v", err)}
-			}
-		}
-
-		// Create deleted item with all metadata
-		item := types.DeletedItem{
-			ID:           id,
-			OriginalPath: absPath,
-			DeleteDate:   now,
-			CachePath:    cachePath,
-			IsDirectory:  isDir,
-			IsSymlink:    isSymlink,
-			LinkTarget:   linkTarget,
-			FileCount:    fileCount,
-			Size:         size,
-		}
-
-		// Update index
-		if err := helpers.AddToIndex(item, config); err != nil {
-			return types.FileMoveMsg{Err: fmt.Errorf("failed to update index: %v", err)}
-		}
-
-		// Log the operation
-		if config.Logging.Enabled {
-			helpers.LogOperation("DELETE", item, config)
-		}
-
-		return types.FileMoveMsg{Item: item, Err: nil}
+		// On a partial failure the item comes with the error, see MoveToCache
+		item, err := helpers.MoveToCache(filename, config, opts)
+		return types.FileMoveMsg{Item: item, Err: err}
 	}
 }
 
-func cleanupOldFiles(config types.Config) tea.Cmd {
+// checkPinnedBeforeClear counts pinned items so a clear can ask for an
+// extra confirmation before destroying them.
+func checkPinnedBeforeClear(config types.Config) tea.Cmd {
 	return func() tea.Msg {
-		cutoffDays := time.Duration(config.Cache.Days) * 24 * time.Hour
-		cutoff := time.Now().Add(-cutoffDays)
-
 		index, err := helpers.LoadIndex(config)
 		if err != nil {
 			return types.ErrorMsg(fmt.Sprintf("Error loading index: %v", err))
 		}
+		return types.ClearCheckMsg{PinnedCount: helpers.CountPinned(index), Items: index.Items}
+	}
+}
 
-		var remainingItems []types.DeletedItem
-		for _, item := range index.Items {
-			if item.DeleteDate.Before(cutoff) {
-				// Remove the actual file or directory
-				if item.IsDirectory {
-					os.RemoveAll(item.CachePath)
-				} else {
-					os.Remove(item.CachePath)
-				}
-
-				// Log cleanup
-				if config.Logging.Enabled {
-					helpers.LogOperation("CLEANUP", item, config)
-				}
-			} else {
-				remainingItems = append(remainingItems, item)
-			}
+// prePurgeHook runs the pre_purge hook on the items a purge would remove.
+func prePurgeHook(daysStr string, config types.Config, opts types.Options) tea.Cmd {
+	return func() tea.Msg {
+		items, err := helpers.FindPurgeItems(daysStr, config)
+		if err != nil {
+			return types.PurgeMsg{Err: err}
 		}
+		err = helpers.RunHook(helpers.HookPrePurge, nil, items, config, opts)
+		return types.HookMsg{Event: helpers.HookPrePurge, Err: err}
+	}
+}
 
-		// Update index
-		index.Items = remainingItems
-		if err := helpers.SaveIndex(index, config); err != nil {
-			return types.ErrorMsg(fmt.Sprintf("Error updating index: %v", err))
+func cleanupOldFiles(config types.Config) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := helpers.CleanupExpired(config); err != nil {
+			return types.ErrorMsg(fmt.Sprintf("Error cleaning up cache: %v", err))
 		}
-
 		return types.CleanupMsg{}
 	}
 }
@@ -551,16 +574,37 @@ func (m *Model) renderCheckingState(content *strings.Builder) {
 }
 
 func (m *Model) renderConfirmingState(content *strings.Builder, contentWidth int) {
-	if m.Operation == "restore" {
+	if m.Operation == "clear" {
+		m.renderClearConfirmation(content)
+	} else if m.Operation == "restore" {
 		m.renderRestoreConfirmation(content)
 	} else {
 		m.renderDeleteConfirmation(content, contentWidth)
 	}
 
 	content.WriteString("\n")
+	if m.needsTypedConfirm() {
+		content.WriteString(m.Styles.Question.Render("Type 'yes' and press Enter to delete anyway: " + m.ConfirmInput))
+		content.WriteString("\n")
+		content.WriteString(m.Styles.Help.Render("Press 'n', Esc or Ctrl+C to cancel"))
+		return
+	}
 	content.WriteString(m.Styles.Help.Render("Press 'y' to confirm, 'n' to cancel, or 'q' to quit"))
 }
 
+func (m *Model) renderClearConfirmation(content *strings.Builder) {
+	content.WriteString(m.Styles.Question.Render("Are you sure you want to clear the entire cache?"))
+	content.WriteString("\n\n")
+
+	icon := "PINNED:"
+	if m.Config.UI.Progress.ShowEmoji {
+		icon = "📌"
+	}
+	warning := fmt.Sprintf("%s %d pinned item(s) will be permanently deleted too", icon, m.PinnedCount)
+	content.WriteString(m.Styles.Warning.Render(warning))
+	content.WriteString("\n")
+}
+
 func (m *Model) renderRestoreConfirmation(content *strings.Builder) {
 	content.WriteString(m.Styles.Question.Render("Are you sure you want to restore the following items?"))
 	content.WriteString("\n")
@@ -583,6 +627,9 @@ func (m *Model) buildRestoreItemsList() string {
 		listContent.WriteString(icon)
 		listContent.WriteString(m.Styles.Filename.Render(item.OriginalPath))
 		listContent.WriteString(m.Styles.Info.Render(fmt.Sprintf(" (deleted: %s)", item.DeleteDate.Format("2006-01-02 15:04"))))
+		if len(item.Tags) > 0 {
+			listContent.WriteString(m.Styles.Help.UnsetMarginTop().Render(" #" + strings.Join(item.Tags, " #")))
+		}
 		listContent.WriteString("\n")
 	}
 
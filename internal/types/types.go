@@ -12,38 +12,60 @@ import (
 )
 
 // Config holds the user configuration loaded from the config file.
+// The doc tags describe each key in the generated config reference.
 type Config struct {
 	Cache struct {
-		Directory string `toml:"directory"`
-		Days      int    `toml:"days"`
-		NoConfirm bool   `toml:"no_confirm"`
+		Directory string `toml:"directory" doc:"Where deleted files are stored, by default $XDG_DATA_HOME/vanish. Do not point this at a folder holding other data"`
+		Days      int    `toml:"days" doc:"Days to keep deleted files before automatic cleanup"`
+		NoConfirm bool   `toml:"no_confirm" doc:"Skip confirmation prompts"`
 	} `toml:"cache"`
 	Logging struct {
-		Enabled   bool   `toml:"enabled"`
-		Directory string `toml:"directory"`
+		Enabled   bool   `toml:"enabled" doc:"Write every operation to vanish.log"`
+		Directory string `toml:"directory" doc:"Directory holding vanish.log, by default $XDG_STATE_HOME/vanish"`
+		Format    string `toml:"format" doc:"Log line format: json (one object per line) or text"`
+		Level     string `toml:"level" doc:"Least severe records written: debug, info, warn or error"`
+		MaxSize   int    `toml:"max_size" doc:"Rotate vanish.log once it reaches this many megabytes, 0 never rotates"`
+		MaxFiles  int    `toml:"max_files" doc:"Rotated logs to keep, 0 keeps all"`
+		MaxAge    int    `toml:"max_age" doc:"Days to keep rotated logs, 0 keeps them regardless of age"`
+		Compress  bool   `toml:"compress" doc:"Gzip rotated logs"`
+		Audit     bool   `toml:"audit" doc:"Chain every record to the previous one by hash (forces json), check with vx --audit-verify"`
 	} `toml:"logging"`
-	// Notifications struct {
-	// 	DesktopEnabled bool `toml:"desktop_enabled"`
-	// 	NotifySuccess  bool `toml:"notify_success"`
-	// 	NotifyErrors   bool `toml:"notify_errors"`
-	// } `toml:"notifications"`
+	Safety struct {
+		Protected []string `toml:"protected" doc:"Globs that vx refuses to delete unless --allow-protected is given"`
+	} `toml:"safety"`
+	Hooks struct {
+		PreDelete   string `toml:"pre_delete" doc:"Command run before deleting, a failure cancels the delete"`
+		PostDelete  string `toml:"post_delete" doc:"Command run after deleting"`
+		PreRestore  string `toml:"pre_restore" doc:"Command run before restoring, a failure cancels the restore"`
+		PostRestore string `toml:"post_restore" doc:"Command run after restoring"`
+		PrePurge    string `toml:"pre_purge" doc:"Command run before purging, a failure cancels the purge"`
+		PostPurge   string `toml:"post_purge" doc:"Command run after purging"`
+		PostClear   string `toml:"post_clear" doc:"Command run after clearing the cache"`
+		Timeout     int    `toml:"timeout" doc:"Seconds a hook may run before it is killed, 0 for no limit"`
+	} `toml:"hooks"`
+	Notifications struct {
+		DesktopEnabled bool `toml:"desktop_enabled" doc:"Show desktop notifications over D-Bus"`
+		NotifySuccess  bool `toml:"notify_success" doc:"Notify when a long delete or restore finishes and when old items are cleaned up"`
+		NotifyErrors   bool `toml:"notify_errors" doc:"Notify when an operation fails"`
+		MinSeconds     int  `toml:"min_seconds" doc:"Seconds a delete or restore must run before its completion is notified"`
+	} `toml:"notifications"`
 	UI struct {
-		Theme  string `toml:"theme"` // "default", "dark", "light", "cyberpunk", "minimal"
+		Theme  string `toml:"theme" doc:"Built-in theme, see vx themes"` // "default", "dark", "light", "cyberpunk", "minimal"
 		Colors struct {
-			Primary   string `toml:"primary"`
-			Secondary string `toml:"secondary"`
-			Success   string `toml:"success"`
-			Warning   string `toml:"warning"`
-			Error     string `toml:"error"`
-			Text      string `toml:"text"`
-			Muted     string `toml:"muted"`
-			Border    string `toml:"border"`
-			Highlight string `toml:"highlight"`
+			Primary   string `toml:"primary" doc:"Main accent color"`
+			Secondary string `toml:"secondary" doc:"Secondary accent color"`
+			Success   string `toml:"success" doc:"Success messages"`
+			Warning   string `toml:"warning" doc:"Warning messages"`
+			Error     string `toml:"error" doc:"Error messages"`
+			Text      string `toml:"text" doc:"Main text color"`
+			Muted     string `toml:"muted" doc:"Muted and help text"`
+			Border    string `toml:"border" doc:"Border color"`
+			Highlight string `toml:"highlight" doc:"Highlighted file names"`
 		} `toml:"colors"`
 		Progress struct {
-			Style     string `toml:"style"` // "gradient", "solid", "rainbow"
-			ShowEmoji bool   `toml:"show_emoji"`
-			Animation bool   `toml:"animation"`
+			Style     string `toml:"style" doc:"Progress bar style: gradient, solid or rainbow"` // "gradient", "solid", "rainbow"
+			ShowEmoji bool   `toml:"show_emoji" doc:"Show emoji in progress messages"`
+			Animation bool   `toml:"animation" doc:"Animate the progress bar"`
 		} `toml:"progress"`
 	} `toml:"ui"`
 }
@@ -59,6 +81,27 @@ type DeletedItem struct {
 	LinkTarget   string    `json:"link_target,omitempty"` // Only populated for symlinks
 	FileCount    int       `json:"file_count,omitempty"`
 	Size         int64     `json:"size"`
+	Pinned       bool      `json:"pinned,omitempty"` // Pinned items are never expired by cleanup or purge
+	Tags         []string  `json:"tags,omitempty"`
+	Note         string    `json:"note,omitempty"`       // Free-text reason for the deletion
+	BatchID      string    `json:"batch_id,omitempty"`   // Shared by items deleted together with --match
+	KeptPaths    []string  `json:"kept_paths,omitempty"` // Children left in place by .vanishignore, relative to OriginalPath
+	GitRepo      string    `json:"git_repo,omitempty"`   // Root of the git working tree the item was deleted from
+	GitHead      string    `json:"git_head,omitempty"`   // HEAD commit of that working tree at deletion time
+}
+
+// GitChange is a file with work git has not recorded yet.
+type GitChange struct {
+	Path  string `json:"path"`  // Relative to the repository root
+	State string `json:"state"` // "modified", "staged", "staged, modified" or "untracked"
+}
+
+// GitStatus describes the git working tree a path belongs to.
+type GitStatus struct {
+	Root    string      `json:"root"`
+	Head    string      `json:"head,omitempty"`
+	Changes []GitChange `json:"changes,omitempty"`
+	Error   string      `json:"error,omitempty"` // Set when the changes could not be checked
 }
 
 // Index represents the global index file
@@ -66,13 +109,54 @@ type Index struct {
 	Items []DeletedItem `json:"items"`
 }
 
+// Options holds the per-invocation flags shared by the TUI and headless runners.
+type Options struct {
+	NoConfirm        bool
+	AllowProtected   bool     // Lift soft protections (home, mount points, [safety] globs)
+	AllowUncommitted bool     // Delete uncommitted git changes without typing "yes"
+	Tags             []string // Tags to attach to deleted items, or to filter by on restore
+	Note             string   // Note to attach to deleted items
+	DryRun           bool     // Only report what would happen
+	BatchID          string   // Recorded on every deleted item when set
+	NoHooks          bool     // Skip the [hooks] commands
+	Verbosity        int      // -v (1) or -vv (2), lowers the [logging] level to info or debug
+}
+
 // FileInfo holds information about a file to be deleted
 type FileInfo struct {
-	Path        string
-	IsDirectory bool
-	FileCount   int
-	Exists      bool
-	Error       string
+	Path            string
+	IsDirectory     bool
+	FileCount       int
+	Exists          bool
+	Error           string
+	Protected       bool   // Refused because the path is protected
+	ProtectedReason string // Set for protected paths, even when overridden
+	Size            int64
+	ModTime         time.Time
+	Kept            []string // Children a .vanishignore leaves in place, relative to Path
+	Git             *GitStatus
+}
+
+// PlanAction is a single step an operation would perform, as reported by a dry run.
+type PlanAction struct {
+	Action      string     `json:"action"` // "move", "restore", "purge", "cleanup", "clear", "refuse" or "skip"
+	Path        string     `json:"path"`
+	Target      string     `json:"target,omitempty"` // Where the item would end up
+	ID          string     `json:"id,omitempty"`
+	IsDirectory bool       `json:"is_directory"`
+	Size        int64      `json:"size"`
+	Conflict    bool       `json:"conflict,omitempty"` // Restore target already exists and gets renamed
+	Reason      string     `json:"reason,omitempty"`   // Why the item is refused, skipped or renamed
+	Kept        []string   `json:"kept,omitempty"`     // Children a .vanishignore leaves in place
+	Git         *GitStatus `json:"git,omitempty"`      // Working tree and uncommitted changes of the path
+}
+
+// Plan describes everything an operation would do without doing it.
+type Plan struct {
+	Operation  string       `json:"operation"`
+	Actions    []PlanAction `json:"actions"`
+	Expiring   []PlanAction `json:"expiring,omitempty"` // Items auto-cleanup would remove afterwards
+	TotalBytes int64        `json:"total_bytes"`        // Bytes moved, restored or freed
 }
 
 // ThemeStyles holds all the styled components used in the TUI
@@ -123,6 +207,12 @@ type RestoreMsg struct {
 // CleanupMsg indicates that a cleanup action has occurred.
 type CleanupMsg struct{}
 
+// ClearCheckMsg reports how many pinned items a pending clear would destroy.
+type ClearCheckMsg struct {
+	PinnedCount int
+	Items       []DeletedItem // everything the clear removes
+}
+
 // ClearMsg represents the result of clearing cached files.
 type ClearMsg struct {
 	Err error
@@ -131,12 +221,62 @@ type ClearMsg struct {
 // PurgeMsg contains information about files purged from the cache.
 type PurgeMsg struct {
 	PurgedCount int
+	Items       []DeletedItem // the purged items
 	Err         error
 }
 
+// LogEntry is one line of vanish.log.
+type LogEntry struct {
+	Time         time.Time `json:"time"`
+	Level        string    `json:"level"` // debug, info, warn or error
+	Operation    string    `json:"op"`    // DELETE, RESTORE, PURGE, CLEANUP, CLEAR_ALL, HOOK, FS, ...
+	ItemID       string    `json:"item_id,omitempty"`
+	BatchID      string    `json:"batch_id,omitempty"`
+	OriginalPath string    `json:"original_path,omitempty"`
+	CachePath    string    `json:"cache_path,omitempty"`
+	Size         int64     `json:"size,omitempty"`
+	IsDirectory  bool      `json:"is_directory,omitempty"`
+	Message      string    `json:"message,omitempty"` // For entries not about a single item
+	DurationMS   float64   `json:"duration_ms,omitempty"`
+	User         string    `json:"user"`
+	PID          int       `json:"pid"`
+	Outcome      string    `json:"outcome"` // "ok" or "error"
+	Error        string    `json:"error,omitempty"`
+
+	// Audit mode only
+	Seq         int64  `json:"seq,omitempty"`
+	PrevHash    string `json:"prev_hash,omitempty"`
+	ContentHash string `json:"content_hash,omitempty"` // sha256 of the deleted or restored item
+	Hash        string `json:"hash,omitempty"`         // sha256 of the line without it, must stay the last field
+}
+
+// HookPayload is written as JSON to the stdin of a [hooks] command.
+type HookPayload struct {
+	Event string        `json:"event"`
+	Paths []string      `json:"paths"`           // Paths about to be deleted, or the original paths of Items
+	Items []DeletedItem `json:"items,omitempty"` // Cached items the operation works on
+}
+
+// HookMsg reports the result of running a hook.
+type HookMsg struct {
+	Event string
+	Err   error
+}
+
+// PlanMsg carries the result of a dry run.
+type PlanMsg struct {
+	Plan Plan
+}
+
 // ErrorMsg is a generic error message used across the application.
 type ErrorMsg string
 
+// IsExpired reports whether the item was deleted before the cutoff and is
+// therefore eligible for cleanup. Pinned items never expire.
+func (item DeletedItem) IsExpired(cutoff time.Time) bool {
+	return !item.Pinned && item.DeleteDate.Before(cutoff)
+}
+
 // ItemType returns a human-readable string describing the item type
 func (item DeletedItem) ItemType() string {
 	if item.IsSymlink {
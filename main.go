@@ -1,43 +1,63 @@
 // Package main is the entry point for the vanish program
 package main
 
+//go:generate go run . --gen-docs docs/reference
+
 import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"vanish/cmd/commands"
 	"vanish/internal/config"
+	"vanish/internal/helpers"
 	"vanish/internal/tui"
 )
 
 func main() {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Error loading config: %v", err)
+	args := os.Args[1:]
+	asRm := filepath.Base(os.Args[0]) == "rm"
+
+	// Completion runs on every Tab press, so it must not write or warn
+	if !asRm && len(args) > 0 && args[0] == "__complete" {
+		os.Exit(command.RunComplete(args[1:], config.Peek()))
+	}
+
+	// vx config check reports the problems itself
+	if !asRm {
+		sets, dryRun := command.ConfigOverrides(args)
+		config.SetFlagOverrides(sets)
+		config.SetDryRun(dryRun)
+	}
+	cfg, problems, err := config.Load()
+	if !command.LoadsOwnConfig(args) {
+		if err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", p)
+		}
+	}
+
+	// Invoked as rm (e.g. through a symlink): speak rm only
+	if asRm {
+		os.Exit(command.RunRm(args, cfg))
 	}
 
-	args := os.Args[1:]
 	if len(args) == 0 {
-		command.ShowUsage(cfg)
+		command.ShowUsageSmart(cfg)
 		return
 	}
 
 	parsed := command.ParseArgs(args, cfg)
-
-	// Validate
-	if parsed.Operation == "" || len(parsed.Filenames) == 0 {
-		if parsed.Operation != "clear" {
-			command.ShowUsage(cfg)
-			os.Exit(1)
-		}
-	}
+	cfg = helpers.WithVerbosity(cfg, parsed.Verbosity)
 
 	// Check if headless mode is enabled
 	if parsed.Headless {
 		// Run without TUI
-		if err := tui.ExecuteHeadless(parsed.Filenames, parsed.Operation, cfg); err != nil {
+		if err := tui.ExecuteHeadless(parsed.Filenames, parsed.Operation, cfg, parsed.Options()); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -45,12 +65,18 @@ func main() {
 	}
 
 	// Initialize and run TUI (normal mode)
-	m, err := tui.InitialModel(parsed.Filenames, parsed.Operation, parsed.NoConfirm)
+	m, err := tui.InitialModel(parsed.Filenames, parsed.Operation, parsed.Options())
 	if err != nil {
 		log.Fatalf("Error initializing: %v", err)
 	}
 
-	p := tea.NewProgram(m)
+	var progOpts []tea.ProgramOption
+	if parsed.StdinMode != "" {
+		// stdin was consumed by --stdin, so keys come from the terminal itself
+		progOpts = append(progOpts, tea.WithInputTTY())
+	}
+
+	p := tea.NewProgram(m, progOpts...)
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("Error running program: %v", err)
 	}